@@ -0,0 +1,205 @@
+package parallel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+type adminProcess struct {
+	Name        string            `json:"name"`
+	State       ProcessState      `json:"state"`
+	Version     string            `json:"version,omitempty"`
+	Restarts    int               `json:"restarts"`
+	Quarantined bool              `json:"quarantined,omitempty"`
+	Progress    *Progress         `json:"progress,omitempty"`
+	Labels      map[string]string `json:"labels"`
+}
+
+// AdminHandler serves the HTTP admin API of the conductor:
+//
+//	GET  /status                        the HealthHandler response
+//	GET  /events                        the EventStreamHandler stream
+//	GET  /processes                     every process with its state and labels
+//	GET  /buildinfo                     BuildInfo
+//	POST /processes/{name}/restart      Restart, returning once it is ready again
+//	POST /processes/{name}/stop         Handle.Stop
+//	POST /restart                       RollingRestart, ?concurrency=N at a time
+//	GET  /pools/{name}/jobs             Pool.Jobs of the pool called name
+//	GET  /pools/{name}/jobs/{id}        Pool.Status
+//	POST /pools/{name}/jobs/{id}/cancel Pool.Cancel, responding with the status
+//	GET  /audit                         AuditLog
+//
+// With WithAuthenticator, every request is authenticated, and requests
+// other than GET require RoleOperate. Control actions are attributed to the
+// authenticated caller or else to the caller's address, unless a
+// middleware has already set an actor with WithActor.
+//
+// Mount it under a prefix with http.StripPrefix. The API can restart and
+// stop processes, so do not expose it unauthenticated.
+func (c *Conductor) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /status", c.HealthHandler())
+	mux.Handle("GET /events", c.EventStreamHandler())
+	mux.HandleFunc("GET /processes", func(w http.ResponseWriter, r *http.Request) {
+		var processes []adminProcess
+		for _, h := range c.Processes() {
+			processes = append(processes, adminProcessOf(h))
+		}
+
+		writeJSON(w, http.StatusOK, processes)
+	})
+
+	mux.HandleFunc("GET /buildinfo", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, c.BuildInfo())
+	})
+
+	mux.HandleFunc("POST /restart", func(w http.ResponseWriter, r *http.Request) {
+		var opts RollingRestartOptions
+		if n, err := strconv.Atoi(r.URL.Query().Get("concurrency")); err == nil {
+			opts.Concurrency = n
+		}
+
+		if err := c.RollingRestart(r.Context(), opts); err != nil {
+			writeJSON(w, adminStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("POST /processes/{name}/restart", c.adminAction(c.Restart))
+	mux.HandleFunc("POST /processes/{name}/stop", c.adminAction(func(ctx context.Context, name string) error {
+		e, err := c.control(name)
+		if err != nil {
+			return err
+		}
+
+		h := &Handle{conductor: c, entry: e}
+		return h.Stop(ctx)
+	}))
+
+	mux.HandleFunc("GET /pools/{name}/jobs", func(w http.ResponseWriter, r *http.Request) {
+		p, err := c.pool(r.PathValue("name"))
+		if err != nil {
+			writeJSON(w, adminStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, p.Jobs())
+	})
+
+	mux.HandleFunc("GET /pools/{name}/jobs/{id}", c.adminJob(func(ctx context.Context, p *Pool, id JobID) error { return nil }))
+	mux.HandleFunc("POST /pools/{name}/jobs/{id}/cancel", c.adminJob(func(ctx context.Context, p *Pool, id JobID) error {
+		err := p.Cancel(id)
+		c.audit(ctx, "cancel_job", fmt.Sprintf("%s/%d", p.name, id), err)
+		return err
+	}))
+	mux.HandleFunc("GET /audit", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, c.AuditLog())
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, ok := c.authorizeHTTP(w, r)
+		if !ok {
+			return
+		}
+
+		if ActorFrom(r.Context()) == "" {
+			r = r.WithContext(WithActor(r.Context(), "http:"+r.RemoteAddr))
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// adminJob serves an action on the pool job named in the path, responding
+// with the status of the job afterwards.
+func (c *Conductor) adminJob(action func(ctx context.Context, p *Pool, id JobID) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, err := c.pool(r.PathValue("name"))
+		if err != nil {
+			writeJSON(w, adminStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+
+		n, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid job id"})
+			return
+		}
+
+		id := JobID(n)
+		if err := action(r.Context(), p, id); err != nil {
+			writeJSON(w, adminStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+
+		status, err := p.Status(id)
+		if err != nil {
+			writeJSON(w, adminStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, status)
+	}
+}
+
+// adminAction serves a control action on the process named in the path,
+// responding with the process as it is afterwards.
+func (c *Conductor) adminAction(action func(ctx context.Context, name string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if err := action(r.Context(), name); err != nil {
+			writeJSON(w, adminStatus(err), map[string]string{"error": err.Error()})
+			return
+		}
+
+		h, _ := c.Lookup(name)
+		writeJSON(w, http.StatusOK, adminProcessOf(h))
+	}
+}
+
+func adminProcessOf(h *Handle) adminProcess {
+	var progress *Progress
+	if p, ok := h.Progress(); ok {
+		progress = &p
+	}
+
+	return adminProcess{
+		Name:        h.Name(),
+		State:       h.State(),
+		Version:     h.Version(),
+		Restarts:    h.Restarts(),
+		Quarantined: h.Quarantined(),
+		Progress:    progress,
+		Labels:      h.Labels(),
+	}
+}
+
+// adminStatus maps a control action error to an HTTP status code.
+func adminStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrUnknownProcess), errors.Is(err, ErrUnknownJob):
+		return http.StatusNotFound
+	case errors.Is(err, ErrNotRunning),
+		errors.Is(err, ErrProcessBusy),
+		errors.Is(err, ErrProcessSkipped),
+		errors.Is(err, ErrQuarantined),
+		errors.Is(err, ErrJobFinished):
+		return http.StatusConflict
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
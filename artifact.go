@@ -0,0 +1,165 @@
+package parallel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StartupReportEnv names the environment variable that, when set, makes
+// the conductor write a JSON startup report once startup completes. Its
+// value is a file path, or "fd:N" to write to an inherited file descriptor.
+const StartupReportEnv = "PARALLEL_STARTUP_REPORT"
+
+// WithStartupReportFile writes the JSON startup report to path. The
+// PARALLEL_STARTUP_REPORT environment variable takes precedence.
+func WithStartupReportFile(path string) Option {
+	return func(c *Conductor) {
+		c.reportTarget = path
+	}
+}
+
+// WithConfigDigest records a SHA-256 digest of cfg's JSON encoding in the
+// startup report, so deployment tooling can tell which configuration a
+// binary started with without seeing the configuration itself.
+func WithConfigDigest(cfg any) Option {
+	return func(c *Conductor) {
+		b, err := json.Marshal(cfg)
+		if err != nil {
+			c.log.Error("failed to encode config for digest", "error", err)
+			return
+		}
+
+		sum := sha256.Sum256(b)
+		c.configDigest = "sha256:" + hex.EncodeToString(sum[:])
+	}
+}
+
+type artifactModule struct {
+	Path     string `json:"path,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Revision string `json:"revision,omitempty"`
+}
+
+type artifactProcess struct {
+	Name          string            `json:"name"`
+	State         ProcessState      `json:"state"`
+	Version       string            `json:"version,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Address       string            `json:"address,omitempty"`
+	StartedAt     time.Time         `json:"started_at"`
+	TimeToReadyMS float64           `json:"time_to_ready_ms"`
+	WarmupMS      float64           `json:"warmup_ms,omitempty"`
+}
+
+type startupArtifact struct {
+	Started      time.Time         `json:"started"`
+	DurationMS   float64           `json:"duration_ms"`
+	GoVersion    string            `json:"go_version"`
+	Module       artifactModule    `json:"module"`
+	ConfigDigest string            `json:"config_digest,omitempty"`
+	Processes    []artifactProcess `json:"processes"`
+}
+
+// writeStartupArtifact writes the startup report of t to the configured
+// target, if any.
+func (c *Conductor) writeStartupArtifact(t *startupTracker) {
+	c.mu.Lock()
+	target := c.reportTarget
+	c.mu.Unlock()
+
+	if env := os.Getenv(StartupReportEnv); env != "" {
+		target = env
+	}
+
+	if target == "" {
+		return
+	}
+
+	report := t.snapshot()
+	info := c.BuildInfo()
+	artifact := startupArtifact{
+		Started:    t.began,
+		DurationMS: float64(report.Duration) / float64(time.Millisecond),
+		GoVersion:  info.GoVersion,
+		Module: artifactModule{
+			Path:     info.Module,
+			Version:  info.Version,
+			Revision: info.Revision,
+		},
+		ConfigDigest: info.ConfigDigest,
+	}
+
+	for _, timing := range report.Processes {
+		p := artifactProcess{
+			Name:          timing.Process,
+			StartedAt:     t.began.Add(timing.TimeToStart),
+			TimeToReadyMS: float64(timing.TimeToReady) / float64(time.Millisecond),
+			WarmupMS:      float64(timing.Warmup) / float64(time.Millisecond),
+		}
+
+		if h, ok := c.Lookup(timing.Process); ok {
+			p.State = h.State()
+			p.Version = h.Version()
+			p.Labels = h.Labels()
+			if a, ok := as[Addresser](h.Process()); ok {
+				p.Address = a.Addr()
+			}
+		}
+
+		artifact.Processes = append(artifact.Processes, p)
+	}
+
+	b, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		c.log.Error("failed to encode startup report", "error", err)
+		return
+	}
+
+	if err := writeArtifact(target, append(b, '\n')); err != nil {
+		c.log.Error("failed to write startup report", "target", target, "error", err)
+		return
+	}
+
+	c.log.Info("wrote startup report", "target", target)
+}
+
+// writeArtifact writes b to an "fd:N" target or atomically replaces the
+// file at path.
+func writeArtifact(target string, b []byte) error {
+	if fd, ok := strings.CutPrefix(target, "fd:"); ok {
+		n, err := strconv.Atoi(fd)
+		if err != nil {
+			return fmt.Errorf("invalid file descriptor %q: %w", fd, err)
+		}
+
+		f := os.NewFile(uintptr(n), target)
+		defer f.Close()
+
+		_, err = f.Write(b)
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".startup-report-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), target)
+}
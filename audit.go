@@ -0,0 +1,158 @@
+package parallel
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"time"
+)
+
+// keepAudit is the number of audit records kept for AuditLog.
+const keepAudit = 256
+
+const auditTimeout = 5 * time.Second
+
+// AuditRecord is a control action taken on the conductor, such as a
+// restart through the admin API or a reload on SIGHUP. Target is the
+// process, pool job or signal acted on, and Err the outcome of the action.
+type AuditRecord struct {
+	Time   time.Time
+	Actor  string
+	Action string
+	Target string
+	Err    error
+}
+
+func (r AuditRecord) MarshalJSON() ([]byte, error) {
+	record := struct {
+		Time    time.Time `json:"time"`
+		Actor   string    `json:"actor"`
+		Action  string    `json:"action"`
+		Target  string    `json:"target,omitempty"`
+		Outcome string    `json:"outcome"`
+		Error   string    `json:"error,omitempty"`
+	}{
+		Time:    r.Time,
+		Actor:   r.Actor,
+		Action:  r.Action,
+		Target:  r.Target,
+		Outcome: "ok",
+	}
+
+	if r.Err != nil {
+		record.Outcome = "failed"
+		record.Error = r.Err.Error()
+	}
+
+	return json.Marshal(record)
+}
+
+// AuditSink records control actions, for example to an append-only log
+// kept for compliance.
+type AuditSink interface {
+	Audit(ctx context.Context, r AuditRecord) error
+}
+
+// AuditFunc adapts a function to an AuditSink.
+type AuditFunc func(ctx context.Context, r AuditRecord) error
+
+func (f AuditFunc) Audit(ctx context.Context, r AuditRecord) error {
+	return f(ctx, r)
+}
+
+// FileAuditLog is an AuditSink appending each record to the file at Path as
+// a JSON line.
+type FileAuditLog struct {
+	Path string
+}
+
+func (f FileAuditLog) Audit(ctx context.Context, r AuditRecord) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// WithAuditLog records every control action to sink: restarts, stops,
+// quarantines and releases, rolling restarts, replacements and canaries,
+// reloads, forwarded signals, cancelled pool jobs and shutdowns requested
+// by a signal or a control API. Actions are recorded whether or not a sink
+// is set, and the most recent ones are returned by AuditLog.
+func WithAuditLog(sink AuditSink) Option {
+	return func(c *Conductor) {
+		c.auditSink = sink
+		c.integrations = append(c.integrations, "audit-log")
+	}
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx attributing the control actions taken
+// with it to actor. The admin API and the gRPC control service attribute
+// actions to the authenticated identity or address of the caller, and the
+// conductor to "signal" for actions taken on an OS signal.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor set on ctx with WithActor, or "".
+func ActorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// Audit records a control action taken on the conductor by a control
+// surface of its own, such as a custom CLI. The conductor's own control
+// methods record their actions themselves.
+func (c *Conductor) Audit(ctx context.Context, action, target string, err error) {
+	c.audit(ctx, action, target, err)
+}
+
+func (c *Conductor) audit(ctx context.Context, action, target string, err error) {
+	r := AuditRecord{Time: time.Now(), Actor: ActorFrom(ctx), Action: action, Target: target, Err: err}
+	if r.Actor == "" {
+		r.Actor = "local"
+	}
+
+	c.mu.Lock()
+	c.auditLog = append(c.auditLog, r)
+	if len(c.auditLog) > keepAudit {
+		c.auditLog = slices.Delete(c.auditLog, 0, len(c.auditLog)-keepAudit)
+	}
+	sink := c.auditSink
+	c.mu.Unlock()
+
+	c.log.Info("control action", "actor", r.Actor, "action", action, "target", target, "error", err)
+
+	if sink == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditTimeout)
+	defer cancel()
+
+	if err := sink.Audit(ctx, r); err != nil {
+		c.log.Error("failed to record control action", "action", action, "target", target, "error", err)
+	}
+}
+
+// AuditLog returns the 256 most recent control actions, oldest first.
+func (c *Conductor) AuditLog() []AuditRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return slices.Clone(c.auditLog)
+}
@@ -0,0 +1,206 @@
+package parallel
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrForbidden       = errors.New("forbidden")
+)
+
+// Role is what an authenticated caller may do through the control APIs.
+type Role int
+
+const (
+	// RoleReadOnly may read status, processes, events and job statuses.
+	RoleReadOnly Role = iota + 1
+	// RoleOperate may also restart, stop and shut down.
+	RoleOperate
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleReadOnly:
+		return "read-only"
+	case RoleOperate:
+		return "operate"
+	default:
+		return "none"
+	}
+}
+
+// Identity is an authenticated caller of a control API. Name is the actor
+// its control actions are attributed to in the audit log.
+type Identity struct {
+	Name string
+	Role Role
+}
+
+// Credentials are what a caller of a control API presented: the bearer
+// token of its request and, over mutual TLS, its verified certificate
+// chains.
+type Credentials struct {
+	Token          string
+	VerifiedChains [][]*x509.Certificate
+}
+
+// Authenticator authenticates the callers of the control APIs, failing
+// with ErrUnauthenticated when the credentials are missing or wrong.
+type Authenticator interface {
+	Authenticate(ctx context.Context, creds Credentials) (Identity, error)
+}
+
+// StaticTokens authenticates callers presenting one of its bearer tokens
+// as the identity stored for it.
+type StaticTokens map[string]Identity
+
+func (t StaticTokens) Authenticate(ctx context.Context, creds Credentials) (Identity, error) {
+	if creds.Token == "" {
+		return Identity{}, ErrUnauthenticated
+	}
+
+	var found Identity
+	for token, id := range t {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(creds.Token)) == 1 {
+			found = id
+		}
+	}
+
+	if found.Role == 0 {
+		return Identity{}, ErrUnauthenticated
+	}
+
+	return found, nil
+}
+
+// ClientCertificates authenticates callers presenting a client certificate
+// verified by the server's TLS configuration, by the common name of its
+// subject. Roles maps common names to roles; others are rejected.
+type ClientCertificates struct {
+	Roles map[string]Role
+}
+
+func (c ClientCertificates) Authenticate(ctx context.Context, creds Credentials) (Identity, error) {
+	if len(creds.VerifiedChains) == 0 || len(creds.VerifiedChains[0]) == 0 {
+		return Identity{}, ErrUnauthenticated
+	}
+
+	name := creds.VerifiedChains[0][0].Subject.CommonName
+	role, ok := c.Roles[name]
+	if !ok {
+		return Identity{}, fmt.Errorf("%w: unknown client %s", ErrUnauthenticated, name)
+	}
+
+	return Identity{Name: name, Role: role}, nil
+}
+
+type anyAuthenticator []Authenticator
+
+// AnyOf authenticates callers with the first of auths that accepts their
+// credentials, to allow both tokens and client certificates.
+func AnyOf(auths ...Authenticator) Authenticator {
+	return anyAuthenticator(auths)
+}
+
+func (a anyAuthenticator) Authenticate(ctx context.Context, creds Credentials) (Identity, error) {
+	var errs []error
+	for _, auth := range a {
+		id, err := auth.Authenticate(ctx, creds)
+		if err == nil {
+			return id, nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return Identity{}, ErrUnauthenticated
+	}
+
+	return Identity{}, errors.Join(errs...)
+}
+
+// WithAuthenticator makes the admin API and the gRPC control service
+// authenticate every call with a. Read-only callers may only call the
+// read endpoints; restarting, stopping and shutting down require
+// RoleOperate. Without an authenticator the control APIs are open, so only
+// serve them on trusted interfaces.
+func WithAuthenticator(a Authenticator) Option {
+	return func(c *Conductor) {
+		c.auth = a
+		c.integrations = append(c.integrations, "authentication")
+	}
+}
+
+// Authorize authenticates a call to a control API made with creds and
+// checks that the caller has the role need, returning ctx attributing the
+// call's actions to the caller. It lets every call through when no
+// authenticator is configured. Custom control surfaces use it to enforce
+// the same policy as the built-in ones.
+func (c *Conductor) Authorize(ctx context.Context, creds Credentials, need Role) (context.Context, error) {
+	c.mu.Lock()
+	auth := c.auth
+	c.mu.Unlock()
+
+	if auth == nil {
+		return ctx, nil
+	}
+
+	id, err := auth.Authenticate(ctx, creds)
+	if err != nil {
+		if !errors.Is(err, ErrUnauthenticated) {
+			err = fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+		}
+
+		return ctx, err
+	}
+
+	if id.Role < need {
+		return ctx, fmt.Errorf("%w: %s is %s, %s required", ErrForbidden, id.Name, id.Role, need)
+	}
+
+	return WithActor(ctx, id.Name), nil
+}
+
+// httpCredentials returns the credentials presented with r.
+func httpCredentials(r *http.Request) Credentials {
+	var creds Credentials
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		creds.Token = strings.TrimSpace(token)
+	}
+
+	if r.TLS != nil {
+		creds.VerifiedChains = r.TLS.VerifiedChains
+	}
+
+	return creds
+}
+
+// authorizeHTTP authorizes r, allowing only GET and HEAD requests to
+// read-only callers, and responds with 401 or 403 when it fails.
+func (c *Conductor) authorizeHTTP(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	need := RoleOperate
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		need = RoleReadOnly
+	}
+
+	ctx, err := c.Authorize(r.Context(), httpCredentials(r), need)
+	switch {
+	case errors.Is(err, ErrForbidden):
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		return r, false
+	case err != nil:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="parallel"`)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": ErrUnauthenticated.Error()})
+		return r, false
+	}
+
+	return r.WithContext(ctx), true
+}
@@ -0,0 +1,107 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PoolAutoscale scales the workers of a Pool between MinWorkers and
+// MaxWorkers. Every Interval the pool grows, doubling its workers, while
+// more than TargetQueueDepth jobs per worker are queued or the last job
+// waited longer than TargetWait for a worker, and shrinks by one idle
+// worker while nothing is queued and jobs are picked up within half of
+// TargetWait. Consecutive scalings are at least Cooldown apart.
+//
+// MinWorkers defaults to 1, MaxWorkers to MinWorkers, TargetQueueDepth to
+// 1 when TargetWait is not set either, Interval to 1 second and Cooldown to
+// 10 seconds.
+type PoolAutoscale struct {
+	MinWorkers       int
+	MaxWorkers       int
+	TargetQueueDepth int
+	TargetWait       time.Duration
+	Interval         time.Duration
+	Cooldown         time.Duration
+}
+
+func (a *PoolAutoscale) defaults() {
+	a.MinWorkers = max(a.MinWorkers, 1)
+	a.MaxWorkers = max(a.MaxWorkers, a.MinWorkers)
+
+	if a.TargetQueueDepth <= 0 && a.TargetWait <= 0 {
+		a.TargetQueueDepth = 1
+	}
+
+	if a.Interval <= 0 {
+		a.Interval = time.Second
+	}
+
+	if a.Cooldown <= 0 {
+		a.Cooldown = 10 * time.Second
+	}
+}
+
+// autoscale scales the workers of the pool until it is stopped.
+func (p *Pool) autoscale(ctx context.Context, queues [priorities]chan queuedJob, wg *sync.WaitGroup) {
+	a := p.opts.Autoscale
+
+	p.mu.RLock()
+	closing := p.closing
+	p.mu.RUnlock()
+
+	ticker := time.NewTicker(a.Interval)
+	defer ticker.Stop()
+
+	var scaled time.Time
+	for {
+		select {
+		case <-closing:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if time.Since(scaled) < a.Cooldown {
+			continue
+		}
+
+		workers := int(p.workers.Load())
+		depth := p.QueueDepth()
+		wait := time.Duration(p.lastWait.Load())
+
+		busy := (a.TargetQueueDepth > 0 && depth > a.TargetQueueDepth*workers) ||
+			(a.TargetWait > 0 && wait > a.TargetWait)
+		idle := depth == 0 && (a.TargetWait <= 0 || wait <= a.TargetWait/2)
+
+		switch {
+		case busy && workers < a.MaxWorkers:
+			to := min(max(workers*2, 1), a.MaxWorkers)
+			p.grow(ctx, queues, wg, to-workers)
+			p.scaled(workers, to, depth, wait)
+			scaled = time.Now()
+		case idle && workers > a.MinWorkers:
+			select {
+			case p.shrink <- struct{}{}:
+				p.scaled(workers, workers-1, depth, wait)
+				scaled = time.Now()
+			default:
+			}
+		}
+	}
+}
+
+// scaled reports that the pool went from one number of workers to another.
+func (p *Pool) scaled(from, to, depth int, wait time.Duration) {
+	p.size.Store(int64(to))
+	p.sink().Gauge("pool.workers", float64(to), Tag{Key: "pool", Value: p.name})
+
+	c := p.conductor.Load()
+	if c == nil {
+		return
+	}
+
+	c.log.Info("scaled pool", "process", p.name, "from", from, "to", to, "queue_depth", depth, "wait", wait)
+	c.emit(Event{Type: EventPoolScaled, Process: p.name, Workers: to})
+}
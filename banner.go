@@ -0,0 +1,86 @@
+package parallel
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Addresser is implemented by processes that listen on a network address,
+// which is then included in the startup summary.
+type Addresser interface {
+	Addr() string
+}
+
+// startupSummary returns the attributes of the "startup complete" log line
+// beyond the timings: the processes, their addresses and the integrations
+// that are enabled.
+func (c *Conductor) startupSummary() []any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var names, skipped []string
+	var addrs, versions []any
+	for _, e := range c.entries {
+		if c.skipped[e] != "" {
+			skipped = append(skipped, e.name())
+			continue
+		}
+
+		names = append(names, e.name())
+		if a, ok := as[Addresser](e.process); ok && a.Addr() != "" {
+			addrs = append(addrs, slog.String(e.name(), a.Addr()))
+		}
+
+		if v := versionOf(e.process); v != "" {
+			versions = append(versions, slog.String(e.name(), v))
+		}
+	}
+
+	return []any{
+		"processes", len(names),
+		"names", names,
+		"skipped", skipped,
+		slog.Group("addresses", addrs...),
+		slog.Group("versions", versions...),
+		"integrations", c.enabledIntegrations(),
+	}
+}
+
+// enabledIntegrations lists the optional features configured on c. The
+// caller must hold c.mu.
+func (c *Conductor) enabledIntegrations() []string {
+	var enabled []string
+	if _, ok := c.metrics.(nopSink); !ok {
+		enabled = append(enabled, fmt.Sprintf("metrics:%T", c.metrics))
+	}
+
+	for _, r := range c.reporters {
+		enabled = append(enabled, fmt.Sprintf("error-reporter:%T", r))
+	}
+
+	enabled = append(enabled, c.integrations...)
+
+	for _, e := range c.entries {
+		if e.builtin {
+			enabled = append(enabled, e.name())
+		}
+	}
+
+	if len(c.bulkheads) > 0 {
+		enabled = append(enabled, "bulkheads")
+	}
+
+	if c.quarantine != nil {
+		enabled = append(enabled, "quarantine")
+	}
+
+	if c.dedup != nil {
+		enabled = append(enabled, "error-dedup")
+	}
+
+	if c.contextOnly {
+		enabled = append(enabled, "context-only")
+	}
+
+	return enabled
+}
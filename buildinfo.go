@@ -0,0 +1,65 @@
+package parallel
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// BuildInfo describes exactly what is running: the main module and the VCS
+// state it was built from, the Go toolchain and platform, and when and with
+// which configuration the conductor started.
+type BuildInfo struct {
+	Module       string    `json:"module,omitempty"`
+	Version      string    `json:"version,omitempty"`
+	Revision     string    `json:"revision,omitempty"`
+	RevisionTime string    `json:"revision_time,omitempty"`
+	Modified     bool      `json:"modified,omitempty"`
+	GoVersion    string    `json:"go_version"`
+	OS           string    `json:"os"`
+	Arch         string    `json:"arch"`
+	PID          int       `json:"pid"`
+	Started      time.Time `json:"started"`
+	ConfigDigest string    `json:"config_digest,omitempty"`
+}
+
+// BuildInfo returns the build and runtime information of the binary. The
+// module and VCS fields are empty when the binary was built without module
+// support, Started is zero until the conductor runs, and ConfigDigest is
+// set by WithConfigDigest.
+func (c *Conductor) BuildInfo() BuildInfo {
+	info := BuildInfo{
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		PID:       os.Getpid(),
+	}
+
+	c.mu.Lock()
+	info.ConfigDigest = c.configDigest
+	if c.startup != nil {
+		info.Started = c.startup.began
+	}
+	c.mu.Unlock()
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.Module = bi.Main.Path
+	info.Version = bi.Main.Version
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.RevisionTime = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,214 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type BulkheadAction int
+
+const (
+	// BulkheadStop stops every process of the group and leaves the rest of
+	// the conductor running.
+	BulkheadStop BulkheadAction = iota
+	// BulkheadRestart restarts every process of the group.
+	BulkheadRestart
+)
+
+func (a BulkheadAction) String() string {
+	switch a {
+	case BulkheadStop:
+		return "stop"
+	case BulkheadRestart:
+		return "restart"
+	default:
+		return "unknown"
+	}
+}
+
+// Bulkhead turns a group into an isolated failure domain. When MaxRestarts
+// is positive, a group restarted that many times during a run is stopped on
+// its next failure instead. A fatal failure always stops the group.
+type Bulkhead struct {
+	Action      BulkheadAction
+	MaxRestarts int
+}
+
+// Grouped is implemented by processes that belong to a named group.
+type Grouped interface {
+	Group() string
+}
+
+type groupedProcess struct {
+	Process
+	group string
+}
+
+// InGroup places p in the named group. Groups are reported as the "group"
+// label, and failures inside a group configured with WithBulkhead only
+// affect that group. The members of a group share a Run context of their
+// own, which is cancelled when the group is stopped or restarted.
+func InGroup(p Process, group string) Process {
+	return &groupedProcess{
+		Process: p,
+		group:   group,
+	}
+}
+
+func (g *groupedProcess) Group() string {
+	return g.group
+}
+
+func (g *groupedProcess) Unwrap() Process {
+	return g.Process
+}
+
+func groupOf(p Process) string {
+	if g, ok := as[Grouped](p); ok {
+		return g.Group()
+	}
+
+	return ""
+}
+
+// WithBulkhead makes a failure of any process in group stop or restart only
+// that group instead of shutting down the conductor.
+func WithBulkhead(group string, b Bulkhead) Option {
+	return func(c *Conductor) {
+		if c.bulkheads == nil {
+			c.bulkheads = make(map[string]Bulkhead)
+		}
+
+		c.bulkheads[group] = b
+	}
+}
+
+// isolate handles err within its bulkhead group, if the failed process has
+// one, and reports whether it did.
+func (c *Conductor) isolate(err *Error) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.find(err.Process)
+	if e == nil {
+		return false
+	}
+
+	group := groupOf(e.process)
+	b, ok := c.bulkheads[group]
+	if !ok || group == "" {
+		return false
+	}
+
+	action := b.Action
+	if action == BulkheadRestart && (err.Fatal() || b.MaxRestarts > 0 && c.groupRestarts[group] >= b.MaxRestarts) {
+		action = BulkheadStop
+	}
+
+	if action == BulkheadRestart {
+		if c.groupRestarts == nil {
+			c.groupRestarts = make(map[string]int)
+		}
+
+		c.groupRestarts[group]++
+	}
+
+	var members []*entry
+	for _, m := range c.entries {
+		if groupOf(m.process) == group {
+			members = append(members, m)
+		}
+	}
+
+	c.log.Warn("isolating failed group", "group", group, "process", err.Process, "action", action.String())
+
+	cause := err
+	go func() {
+		c.isolation.Lock()
+		defer c.isolation.Unlock()
+
+		// Members restarted below get a fresh group context; the old one is
+		// cancelled once they are done, ending whatever their previous Runs
+		// left behind without touching the rest of the conductor.
+		c.mu.Lock()
+		scope := c.groups[group]
+		delete(c.groups, group)
+		c.mu.Unlock()
+
+		if scope != nil {
+			defer scope.cancel(cause)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		for _, m := range members {
+			var err error
+			if action == BulkheadRestart {
+				err = c.restart(ctx, m)
+			} else {
+				err = c.stopProcess(ctx, m)
+			}
+
+			if err != nil && !errors.Is(err, ErrNotRunning) {
+				c.log.Error("failed to "+action.String()+" process", "group", group, "process", m.name(), "error", err)
+			}
+		}
+	}()
+
+	return true
+}
+
+// groupScope is the context shared by the members of a group during a run.
+type groupScope struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+}
+
+// groupContext returns the context the members of group run with, deriving
+// it from ctx when the group has none yet. The caller must hold c.mu.
+func (c *Conductor) groupContext(ctx context.Context, group string) context.Context {
+	if s := c.groups[group]; s != nil && s.ctx.Err() == nil {
+		return s.ctx
+	}
+
+	if c.groups == nil {
+		c.groups = make(map[string]*groupScope)
+	}
+
+	gctx, cancel := context.WithCancelCause(ctx)
+	c.groups[group] = &groupScope{ctx: gctx, cancel: cancel}
+	return gctx
+}
+
+// cancelGroups cancels the contexts of every group. The caller must hold
+// c.mu.
+func (c *Conductor) cancelGroups() {
+	for _, s := range c.groups {
+		s.cancel(nil)
+	}
+
+	c.groups = nil
+}
+
+// Health reports the health of every process, joining the errors of the
+// unhealthy ones. Skipped processes, lazy processes that are not running
+// and windowed processes outside their window are left out. Processes
+// stopped because their bulkhead group failed are unhealthy while the rest
+// of the conductor keeps running.
+func (c *Conductor) Health(ctx context.Context) error {
+	var errs []error
+	for _, h := range c.Processes() {
+		switch state := h.State(); {
+		case state == ProcessSkipped, state == ProcessScheduledOff, state == ProcessIdle && isLazy(h.entry.process):
+			continue
+		}
+
+		if err := h.Health(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,144 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+)
+
+// Bus is a small pub/sub facility owned by the conductor, through which
+// sibling processes exchange events such as "config reloaded" or "cache
+// invalidated". Messages are published on typed topics with Publish and
+// received with Subscribe.
+type Bus struct {
+	mu     sync.RWMutex
+	topics map[string]map[*subscription]struct{}
+}
+
+type subscription struct {
+	deliver func(msg any)
+	close   func()
+}
+
+// Topic names a stream of messages of type T on a Bus.
+type Topic[T any] struct {
+	name string
+}
+
+// NewTopic returns the topic called name. Topics with the same name and
+// type are the same topic; a subscriber only receives messages of its type.
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{name: name}
+}
+
+// Name returns the name of the topic.
+func (t Topic[T]) Name() string {
+	return t.name
+}
+
+// Bus returns the conductor's bus. Subscriptions are closed once shutdown
+// completes.
+func (c *Conductor) Bus() *Bus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.eventBus()
+}
+
+// eventBus is Bus for a caller holding c.mu.
+func (c *Conductor) eventBus() *Bus {
+	if c.bus == nil {
+		c.bus = &Bus{}
+	}
+
+	return c.bus
+}
+
+// Publish delivers msg to every current subscriber of topic. It never
+// blocks: a subscriber whose buffer is full misses the message.
+func Publish[T any](b *Bus, topic Topic[T], msg T) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for s := range b.topics[topic.name] {
+		s.deliver(msg)
+	}
+}
+
+// Subscribe returns a channel receiving the messages published on topic
+// from now on, with room for buffer messages. The channel is closed when
+// ctx is done or the conductor's shutdown completes, so a process can
+// range over it without tearing the subscription down itself.
+func Subscribe[T any](ctx context.Context, b *Bus, topic Topic[T], buffer int) <-chan T {
+	ch := make(chan T, buffer)
+	done := make(chan struct{})
+
+	s := &subscription{
+		deliver: func(msg any) {
+			v, ok := msg.(T)
+			if !ok {
+				return
+			}
+
+			select {
+			case ch <- v:
+			default:
+			}
+		},
+	}
+
+	var once sync.Once
+	s.close = func() {
+		once.Do(func() {
+			close(done)
+			close(ch)
+		})
+	}
+
+	b.mu.Lock()
+	if b.topics == nil {
+		b.topics = make(map[string]map[*subscription]struct{})
+	}
+
+	if b.topics[topic.name] == nil {
+		b.topics[topic.name] = make(map[*subscription]struct{})
+	}
+
+	b.topics[topic.name][s] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			b.unsubscribe(topic.name, s)
+		case <-done:
+		}
+	}()
+
+	return ch
+}
+
+func (b *Bus) unsubscribe(topic string, s *subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.topics[topic], s)
+	if len(b.topics[topic]) == 0 {
+		delete(b.topics, topic)
+	}
+
+	s.close()
+}
+
+// closeAll closes every subscription.
+func (b *Bus) closeAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, subs := range b.topics {
+		for s := range subs {
+			s.close()
+		}
+	}
+
+	b.topics = nil
+}
@@ -0,0 +1,51 @@
+package parallel
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// neverReady is a process whose Run returns before it becomes ready.
+type neverReady struct {
+	Process
+}
+
+func (neverReady) Ready(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (n neverReady) Unwrap() Process {
+	return n.Process
+}
+
+func TestReplaceForgetsExitedCandidate(t *testing.T) {
+	c := NewConductor(Task("api", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})).With(WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))), WithVirtualSignals())
+
+	c.Run(context.Background())
+	defer func() {
+		c.Shutdown("test")
+		c.ThenStop()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	next := neverReady{Task("api", func(context.Context) error { return nil })}
+	if err := c.Replace(ctx, "api", next); err == nil {
+		t.Fatal("Replace succeeded with a replacement that exited before becoming ready")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if n := len(c.candidates); n != 0 {
+		t.Errorf("%d candidates left after the replacement exited, want 0", n)
+	}
+}
@@ -0,0 +1,239 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"time"
+)
+
+// HealthChecker is implemented by processes that can report whether they are
+// still working correctly after becoming ready.
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// Reloader is implemented by processes that can pick up new configuration
+// without a restart. Reload is called on SIGHUP and by Conductor.Reload.
+type Reloader interface {
+	Reload(ctx context.Context) error
+}
+
+// Drainer is implemented by processes that should stop accepting new work
+// before they are stopped. Drain is called during shutdown, right before
+// Stop.
+type Drainer interface {
+	Drain(ctx context.Context) error
+}
+
+// Signaler is implemented by processes that want to receive the signals
+// forwarded by the conductor (SIGUSR1 and SIGUSR2 on Unix) and those sent
+// with Conductor.Signal.
+type Signaler interface {
+	Signal(ctx context.Context, sig os.Signal) error
+}
+
+// StopTimeouter is implemented by processes whose Stop must not take longer
+// than a given duration, even when the shutdown budget allows it.
+type StopTimeouter interface {
+	StopTimeout() time.Duration
+}
+
+type Capability string
+
+const (
+	CapabilityReadiness      Capability = "readiness"
+	CapabilityHealth         Capability = "health"
+	CapabilityReload         Capability = "reload"
+	CapabilityDrain          Capability = "drain"
+	CapabilitySignal         Capability = "signal"
+	CapabilityStopTimeout    Capability = "stop-timeout"
+	CapabilityDependencies   Capability = "dependencies"
+	CapabilityLabels         Capability = "labels"
+	CapabilityMemoryUsage    Capability = "memory-usage"
+	CapabilityShutdownWeight Capability = "shutdown-weight"
+	CapabilityGroup          Capability = "group"
+	CapabilityAddress        Capability = "address"
+	CapabilityResourceUsage  Capability = "resource-usage"
+	CapabilityProfiles       Capability = "profiles"
+	CapabilityEnabled        Capability = "enabled"
+	CapabilityOnDemand       Capability = "on-demand"
+	CapabilityWarmup         Capability = "warmup"
+	CapabilityVersion        Capability = "version"
+	CapabilityClassifier     Capability = "classifier"
+	CapabilitySchedule       Capability = "schedule"
+	CapabilityProgress       Capability = "progress"
+	CapabilityCheckpoint     Capability = "checkpoint"
+)
+
+// Capabilities reports which optional interfaces p implements. Like the
+// conductor itself, it looks through wrappers that implement
+// Unwrap() Process, so middleware that follows that convention preserves the
+// capabilities of the process it wraps.
+func Capabilities(p Process) []Capability {
+	var caps []Capability
+	check := func(ok bool, c Capability) {
+		if ok {
+			caps = append(caps, c)
+		}
+	}
+
+	_, ok := as[Readier](p)
+	check(ok, CapabilityReadiness)
+	_, ok = as[Warmer](p)
+	check(ok, CapabilityWarmup)
+	_, ok = as[HealthChecker](p)
+	check(ok, CapabilityHealth)
+	_, ok = as[Reloader](p)
+	check(ok, CapabilityReload)
+	_, ok = as[Drainer](p)
+	check(ok, CapabilityDrain)
+	_, ok = as[Signaler](p)
+	check(ok, CapabilitySignal)
+	_, ok = as[StopTimeouter](p)
+	check(ok, CapabilityStopTimeout)
+	_, ok = as[Dependent](p)
+	check(ok, CapabilityDependencies)
+	_, ok = as[Labeled](p)
+	check(ok, CapabilityLabels)
+	_, ok = as[MemoryReporter](p)
+	check(ok, CapabilityMemoryUsage)
+	_, ok = as[ShutdownWeighter](p)
+	check(ok, CapabilityShutdownWeight)
+	_, ok = as[Grouped](p)
+	check(ok, CapabilityGroup)
+	_, ok = as[Addresser](p)
+	check(ok, CapabilityAddress)
+	_, ok = as[UsageReporter](p)
+	check(ok, CapabilityResourceUsage)
+	_, ok = as[Profiled](p)
+	check(ok, CapabilityProfiles)
+	_, ok = as[Enabler](p)
+	check(ok, CapabilityEnabled)
+	_, ok = as[OnDemand](p)
+	check(ok, CapabilityOnDemand)
+	_, ok = as[Versioned](p)
+	check(ok, CapabilityVersion)
+	_, ok = as[Classifier](p)
+	check(ok, CapabilityClassifier)
+	_, ok = as[Windowed](p)
+	check(ok, CapabilitySchedule)
+	_, ok = as[Progresser](p)
+	check(ok, CapabilityProgress)
+	_, ok = as[Checkpointer](p)
+	check(ok, CapabilityCheckpoint)
+
+	return caps
+}
+
+// Health reports the health of the process. Processes that do not implement
+// HealthChecker are healthy while they are running.
+func (h *Handle) Health(ctx context.Context) error {
+	if checker, ok := as[HealthChecker](h.entry.process); ok {
+		return checker.Health(ctx)
+	}
+
+	if state := h.State(); state != ProcessRunning {
+		return errors.New("process is " + string(state))
+	}
+
+	return nil
+}
+
+// Reload calls Reload on every running process implementing Reloader and
+// returns the errors joined.
+func (c *Conductor) Reload(ctx context.Context) (err error) {
+	defer func() { c.audit(ctx, "reload", "", err) }()
+
+	var errs []error
+	for _, h := range c.Processes() {
+		r, ok := as[Reloader](h.entry.process)
+		if !ok || h.State() != ProcessRunning {
+			continue
+		}
+
+		if err := r.Reload(ctx); err != nil {
+			c.log.Error("failed to reload process", "process", h.Name(), "error", err)
+			errs = append(errs, err)
+			continue
+		}
+
+		c.log.Info("reloaded process", "process", h.Name())
+	}
+
+	return errors.Join(errs...)
+}
+
+// Signal forwards sig to every running process implementing Signaler and
+// returns the errors joined.
+func (c *Conductor) Signal(ctx context.Context, sig os.Signal) (err error) {
+	defer func() { c.audit(ctx, "signal", sig.String(), err) }()
+
+	var errs []error
+	for _, h := range c.Processes() {
+		s, ok := as[Signaler](h.entry.process)
+		if !ok || h.State() != ProcessRunning {
+			continue
+		}
+
+		if err := s.Signal(ctx, sig); err != nil {
+			c.log.Error("failed to signal process", "process", h.Name(), "signal", sig.String(), "error", err)
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// relay turns OS signals into Reload and Signal calls while the run is
+// active. It only subscribes to signals that a registered process can
+// handle, so that otherwise SIGHUP keeps its default behavior. The caller
+// must hold c.mu.
+func (c *Conductor) relay(ctx context.Context, done <-chan struct{}) {
+	var sigs []os.Signal
+	for _, e := range c.entries {
+		if _, ok := as[Reloader](e.process); ok && sigReload != nil {
+			sigs = append(sigs, sigReload)
+			break
+		}
+	}
+
+	for _, e := range c.entries {
+		if _, ok := as[Signaler](e.process); ok {
+			sigs = append(sigs, forwardedSignals...)
+			break
+		}
+	}
+
+	sigs = slices.DeleteFunc(sigs, func(sig os.Signal) bool {
+		_, ok := c.signals[sig]
+		return ok
+	})
+
+	if len(sigs) == 0 {
+		return
+	}
+
+	ch := make(chan os.Signal, 1)
+	c.sigsrc.Notify(ch, sigs...)
+
+	ctx = WithActor(ctx, "signal")
+	go func() {
+		defer c.sigsrc.Stop(ch)
+		defer c.crashGuard("signal relay")
+
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-ch:
+				if sig == sigReload {
+					_ = c.Reload(ctx)
+				} else {
+					_ = c.Signal(ctx, sig)
+				}
+			}
+		}
+	}()
+}
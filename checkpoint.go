@@ -0,0 +1,50 @@
+package parallel
+
+// Checkpointer is implemented by processes that hand state over to their
+// next run when the conductor restarts them, so that a consumer resumes
+// from its offsets rather than reprocessing. Checkpoint is called once the
+// old run has returned, and Restore with its result before the new run
+// starts. A failed Checkpoint is logged and the new run starts without
+// state; a failed Restore fails the new run.
+type Checkpointer interface {
+	Checkpoint() ([]byte, error)
+	Restore(state []byte) error
+}
+
+// checkpoint records the state e hands to its next run.
+func (c *Conductor) checkpoint(e *entry) {
+	cp, ok := as[Checkpointer](e.process)
+	if !ok {
+		return
+	}
+
+	state, err := cp.Checkpoint()
+	if err != nil {
+		c.log.Warn("failed to checkpoint process", "process", e.name(), "error", err)
+		state = nil
+	}
+
+	e.mu.Lock()
+	e.handoff, e.hasHandoff = state, err == nil
+	e.mu.Unlock()
+}
+
+// restore hands e the state checkpointed by its previous run, if any.
+func (c *Conductor) restore(e *entry) error {
+	e.mu.Lock()
+	state, ok := e.handoff, e.hasHandoff
+	e.handoff, e.hasHandoff = nil, false
+	e.mu.Unlock()
+
+	cp, isCheckpointer := as[Checkpointer](e.process)
+	if !ok || !isCheckpointer {
+		return nil
+	}
+
+	if err := cp.Restore(state); err != nil {
+		return err
+	}
+
+	c.log.Info("restored process state", "process", e.name(), "bytes", len(state))
+	return nil
+}
@@ -0,0 +1,65 @@
+package parallel
+
+import "errors"
+
+// ErrorClass tells the conductor whether restarting a failed process can
+// help. Transient failures, the default, are restarted by quarantine and
+// bulkhead policies; fatal ones, such as invalid configuration or a port
+// conflict, are not.
+type ErrorClass string
+
+const (
+	ClassTransient ErrorClass = "transient"
+	ClassFatal     ErrorClass = "fatal"
+)
+
+// Classifier is implemented by processes that classify their own failures.
+// Errors that implement Fatal() bool classify themselves and take
+// precedence.
+type Classifier interface {
+	Classify(err error) ErrorClass
+}
+
+type fatalError struct {
+	err error
+}
+
+// Fatal marks err as fatal, so the process that returned it is not
+// restarted.
+func Fatal(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &fatalError{err: err}
+}
+
+func (f *fatalError) Error() string {
+	return f.err.Error()
+}
+
+func (f *fatalError) Unwrap() error {
+	return f.err
+}
+
+func (f *fatalError) Fatal() bool {
+	return true
+}
+
+// IsFatal reports whether any error in err's chain is fatal.
+func IsFatal(err error) bool {
+	var f interface{ Fatal() bool }
+	return errors.As(err, &f) && f.Fatal()
+}
+
+func classify(p Process, err error) ErrorClass {
+	if IsFatal(err) {
+		return ClassFatal
+	}
+
+	if c, ok := as[Classifier](p); ok && c.Classify(err) == ClassFatal {
+		return ClassFatal
+	}
+
+	return ClassTransient
+}
@@ -0,0 +1,310 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+var ErrNotSupported = errors.New("not supported on this platform")
+
+// commandWaitDelay bounds how long a Command waits for the output of a
+// child that has exited, in case grandchildren keep its pipes open.
+const commandWaitDelay = time.Second
+
+// Rlimit is a resource limit applied to a Command's child process, such as
+// syscall.RLIMIT_NOFILE. Limits are only supported on Linux.
+type Rlimit struct {
+	Resource int
+	Soft     uint64
+	Hard     uint64
+}
+
+// EnvProvider resolves environment variables for a Command's child. It is
+// called every time the child starts, so credentials it fetches are fresh
+// after each restart.
+type EnvProvider func(ctx context.Context) (map[string]string, error)
+
+// Secret returns an EnvProvider setting the variable name to the value fn
+// resolves.
+func Secret(name string, fn func(ctx context.Context) (string, error)) EnvProvider {
+	return func(ctx context.Context) (map[string]string, error) {
+		v, err := fn(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s: %w", name, err)
+		}
+
+		return map[string]string{name: v}, nil
+	}
+}
+
+// Command is a Process that supervises an external program. Run starts
+// the program and waits for it to exit, and Stop sends StopSignal and waits
+// until the program exits or the stop context ends, at which point the
+// program is killed.
+type Command struct {
+	name string
+	Path string
+	Args []string
+
+	// Dir is the working directory; empty means the current one.
+	Dir string
+	// Env is added to the inherited environment or, with CleanEnv, is the
+	// whole environment of the child.
+	Env      []string
+	CleanEnv bool
+	// Providers are resolved on every start and override Env. Their values
+	// are never logged.
+	Providers []EnvProvider
+
+	// Rlimits are applied to the child with prlimit(2) right after it
+	// starts. Until then the program runs unlimited, so a program that must
+	// not fork or open files before its limits are in place should be
+	// started through a wrapper such as prlimit(1) instead.
+	Rlimits []Rlimit
+	// Isolate runs the child in its own process group and signals the
+	// whole group, so helpers the child spawns are stopped with it. It is
+	// only supported on Unix.
+	Isolate bool
+	// Cgroup is the path of a cgroup v2 directory to start the child in.
+	// It is only supported on Linux.
+	Cgroup string
+
+	// StopSignal defaults to SIGTERM, or to killing the child where
+	// signals are not supported.
+	StopSignal os.Signal
+
+	Stdout io.Writer
+	Stderr io.Writer
+
+	mu       sync.Mutex
+	process  *os.Process
+	done     chan struct{}
+	stopping bool
+}
+
+// NewCommand returns a Command called name that runs path with args. Its
+// output goes to the conductor's stdout and stderr.
+func NewCommand(name, path string, args ...string) *Command {
+	return &Command{
+		name:   name,
+		Path:   path,
+		Args:   args,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+}
+
+func (c *Command) Name() string {
+	return c.name
+}
+
+func (c *Command) Run(ctx context.Context) error {
+	cmd := exec.Command(c.Path, c.Args...)
+	cmd.Dir = c.Dir
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+	cmd.WaitDelay = commandWaitDelay
+
+	env, err := c.environ(ctx)
+	if err != nil {
+		return err
+	}
+
+	cmd.Env = env
+
+	release, err := c.sandbox(cmd)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	if err := applyRlimits(cmd.Process.Pid, c.Rlimits); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return fmt.Errorf("apply rlimits: %w", err)
+	}
+
+	c.mu.Lock()
+	c.process = cmd.Process
+	c.done = make(chan struct{})
+	c.stopping = false
+	c.mu.Unlock()
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-exited:
+		return c.exited(err)
+	case <-ctx.Done():
+		c.requestStop()
+		return c.exited(<-exited)
+	}
+}
+
+// environ returns the child's environment, resolving the providers. nil
+// means the child inherits the conductor's environment unchanged.
+func (c *Command) environ(ctx context.Context) ([]string, error) {
+	var env []string
+	if c.CleanEnv {
+		env = []string{}
+	} else if len(c.Env) > 0 || len(c.Providers) > 0 {
+		env = os.Environ()
+	}
+
+	env = append(env, c.Env...)
+
+	for _, provide := range c.Providers {
+		vars, err := provide(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("resolve environment: %w", err)
+		}
+
+		for k, v := range vars {
+			env = append(env, k+"="+v)
+		}
+	}
+
+	return env, nil
+}
+
+// exited clears the running process and reports how it exited. Exiting
+// because it was asked to stop is not an error.
+func (c *Command) exited(err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.process = nil
+	close(c.done)
+
+	var exitErr *exec.ExitError
+	if c.stopping && errors.As(err, &exitErr) {
+		return nil
+	}
+
+	return err
+}
+
+func (c *Command) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	p, done := c.process, c.done
+	c.mu.Unlock()
+
+	if p == nil {
+		return nil
+	}
+
+	if err := c.requestStop(); err != nil {
+		return c.kill()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Join(ctx.Err(), c.kill())
+	}
+}
+
+// Signal forwards sig to the child.
+func (c *Command) Signal(ctx context.Context, sig os.Signal) error {
+	return c.signal(sig)
+}
+
+// requestStop sends StopSignal to the child.
+func (c *Command) requestStop() error {
+	sig := c.StopSignal
+	if sig == nil {
+		sig = sigTerminate
+	}
+
+	c.mu.Lock()
+	c.stopping = true
+	c.mu.Unlock()
+
+	return c.signal(sig)
+}
+
+func (c *Command) signal(sig os.Signal) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.process == nil {
+		return nil
+	}
+
+	return signalProcess(c.process, sig, c.Isolate)
+}
+
+func (c *Command) kill() error {
+	return c.signal(os.Kill)
+}
+
+// sandbox applies the isolation options to cmd. The returned function
+// releases any resources held for the start.
+func (c *Command) sandbox(cmd *exec.Cmd) (func(), error) {
+	if c.Isolate {
+		if err := isolate(cmd); err != nil {
+			return nil, fmt.Errorf("isolate: %w", err)
+		}
+	}
+
+	if c.Cgroup == "" {
+		return func() {}, nil
+	}
+
+	release, err := joinCgroup(cmd, c.Cgroup)
+	if err != nil {
+		return nil, fmt.Errorf("cgroup %s: %w", c.Cgroup, err)
+	}
+
+	return release, nil
+}
+
+// ResourceUsage reports the CPU time and resident set size of the child.
+func (c *Command) ResourceUsage() (ResourceUsage, error) {
+	c.mu.Lock()
+	p := c.process
+	c.mu.Unlock()
+
+	if p == nil {
+		return ResourceUsage{}, ErrNotRunning
+	}
+
+	cpu, err := cpuTime(p.Pid)
+	if err != nil {
+		return ResourceUsage{}, err
+	}
+
+	rss, err := residentMemory(p.Pid)
+	if err != nil {
+		return ResourceUsage{}, err
+	}
+
+	return ResourceUsage{CPUTime: cpu, RSS: rss, Sampled: time.Now()}, nil
+}
+
+// MemoryUsage reports the resident set size of the child.
+func (c *Command) MemoryUsage() (uint64, error) {
+	c.mu.Lock()
+	p := c.process
+	c.mu.Unlock()
+
+	if p == nil {
+		return 0, ErrNotRunning
+	}
+
+	return residentMemory(p.Pid)
+}
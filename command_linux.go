@@ -0,0 +1,92 @@
+package parallel
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func applyRlimits(pid int, limits []Rlimit) error {
+	for _, l := range limits {
+		rlimit := unix.Rlimit{Cur: l.Soft, Max: l.Hard}
+		if err := unix.Prlimit(pid, l.Resource, &rlimit, nil); err != nil {
+			return fmt.Errorf("resource %d: %w", l.Resource, err)
+		}
+	}
+
+	return nil
+}
+
+func joinCgroup(cmd *exec.Cmd, path string) (func(), error) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(dir.Fd())
+
+	return func() { dir.Close() }, nil
+}
+
+// clockTicks is the USER_HZ unit of /proc CPU times, which is 100 on every
+// architecture Go supports.
+const clockTicks = 100
+
+// cpuTime reads the user and system CPU time of pid from /proc.
+func cpuTime(pid int) (time.Duration, error) {
+	b, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return 0, err
+	}
+
+	// The command name is in parentheses and may contain spaces, so the
+	// fields are counted from the last closing parenthesis onwards.
+	i := bytes.LastIndexByte(b, ')')
+	fields := bytes.Fields(b[i+1:])
+	if i < 0 || len(fields) < 13 {
+		return 0, fmt.Errorf("unexpected stat format %q", b)
+	}
+
+	var ticks uint64
+	for _, f := range fields[11:13] {
+		n, err := strconv.ParseUint(string(f), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+
+		ticks += n
+	}
+
+	return time.Duration(ticks) * time.Second / clockTicks, nil
+}
+
+// residentMemory reads the resident set size of pid from /proc.
+func residentMemory(pid int) (uint64, error) {
+	b, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/statm")
+	if err != nil {
+		return 0, err
+	}
+
+	fields := bytes.Fields(b)
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("unexpected statm format %q", b)
+	}
+
+	pages, err := strconv.ParseUint(string(fields[1]), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return pages * uint64(os.Getpagesize()), nil
+}
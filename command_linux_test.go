@@ -0,0 +1,33 @@
+package parallel_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/franklad/parallel"
+)
+
+func TestCommandRlimitsInEffect(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "limits")
+
+	// The short sleep keeps the check clear of the window before prlimit.
+	cmd := parallel.NewCommand("limited", "/bin/sh", "-c", `sleep 0.2; ulimit -Sn > "$0"; ulimit -Hn >> "$0"`, out)
+	cmd.Rlimits = []parallel.Rlimit{{Resource: syscall.RLIMIT_NOFILE, Soft: 64, Hard: 128}}
+
+	if err := cmd.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Fields(string(b)); len(got) != 2 || got[0] != "64" || got[1] != "128" {
+		t.Errorf("child limits = %q, want soft 64 and hard 128", got)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !linux
+
+package parallel
+
+import (
+	"os/exec"
+	"time"
+)
+
+func applyRlimits(_ int, limits []Rlimit) error {
+	if len(limits) > 0 {
+		return ErrNotSupported
+	}
+
+	return nil
+}
+
+func joinCgroup(*exec.Cmd, string) (func(), error) {
+	return nil, ErrNotSupported
+}
+
+func cpuTime(int) (time.Duration, error) {
+	return 0, ErrNotSupported
+}
+
+func residentMemory(int) (uint64, error) {
+	return 0, ErrNotSupported
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package parallel
+
+import (
+	"os"
+	"os/exec"
+)
+
+func isolate(*exec.Cmd) error {
+	return ErrNotSupported
+}
+
+func signalProcess(p *os.Process, sig os.Signal, _ bool) error {
+	return p.Signal(sig)
+}
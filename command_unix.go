@@ -0,0 +1,27 @@
+//go:build unix
+
+package parallel
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+func isolate(cmd *exec.Cmd) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	cmd.SysProcAttr.Setpgid = true
+	return nil
+}
+
+func signalProcess(p *os.Process, sig os.Signal, group bool) error {
+	s, ok := sig.(syscall.Signal)
+	if !group || !ok {
+		return p.Signal(sig)
+	}
+
+	return syscall.Kill(-p.Pid, s)
+}
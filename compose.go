@@ -0,0 +1,133 @@
+package parallel
+
+import (
+	"errors"
+	"os"
+)
+
+var ErrAdopted = errors.New("conductor has already been adopted")
+
+// Merge returns a new conductor combining the processes and configuration
+// of cs, as if each had been adopted in turn. An error from Adopt is
+// returned by ThenStop, and Run refuses to start.
+func Merge(cs ...*Conductor) *Conductor {
+	c := NewConductor()
+	if err := c.Adopt(cs...); err != nil {
+		c.mu.Lock()
+		c.misuse = err
+		c.mu.Unlock()
+	}
+
+	return c
+}
+
+// Adopt moves the processes of others into c and applies their options to
+// c, so modules that each build their own conductor can be composed into a
+// single lifecycle. Adopted processes keep the middleware of the conductor
+// they came from and are additionally wrapped by c's own. The logger,
+// metrics sink, shutdown signals and context-only mode of c take precedence
+// over those of others, and processes registered by options, such as the
+// runtime metrics reporter, are only added once.
+//
+// The adopted conductors are left empty and refuse to run with ErrAdopted.
+// Adopt fails with ErrAdopted if a conductor is given twice, is c itself or
+// was adopted before, with ErrAlreadyRunning if any of them is running, and
+// with the validation error if the combined process set is invalid. Nothing
+// is changed when Adopt fails.
+func (c *Conductor) Adopt(others ...*Conductor) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == stateStopping {
+		return ErrStopped
+	}
+
+	seen := make(map[*Conductor]bool)
+	for _, other := range others {
+		if other == c || seen[other] {
+			return ErrAdopted
+		}
+
+		seen[other] = true
+
+		other.mu.Lock()
+		defer other.mu.Unlock()
+
+		if other.adopted {
+			return ErrAdopted
+		}
+
+		if other.state == stateRunning || other.state == stateStopping {
+			return ErrAlreadyRunning
+		}
+	}
+
+	registered := len(c.entries)
+	for _, other := range others {
+		for _, e := range other.entries {
+			if !e.builtin {
+				c.register(e.process)
+			}
+		}
+	}
+
+	if err := c.validate(); err != nil {
+		c.entries = c.entries[:registered]
+		return err
+	}
+
+	log, metrics, contextOnly, sigsrc := c.log, c.metrics, c.contextOnly, c.sigsrc
+	signals := c.signals
+	c.signals = nil
+
+	for _, other := range others {
+		for _, opt := range other.options {
+			c.adoptOption(opt)
+		}
+
+		other.entries = nil
+		other.options = nil
+		other.adopted = true
+	}
+
+	for sig, policy := range signals {
+		if c.signals == nil {
+			c.signals = make(map[os.Signal]ShutdownPolicy)
+		}
+
+		c.signals[sig] = policy
+	}
+
+	c.log, c.metrics, c.contextOnly, c.sigsrc = log, metrics, contextOnly, sigsrc
+
+	c.log.Info("adopted conductors", "conductors", len(others), "processes", len(c.entries)-registered)
+
+	if c.state == stateRunning {
+		for _, e := range c.entries[registered:] {
+			e.resetReady()
+			c.launch(c.ctx, e)
+		}
+	}
+
+	return nil
+}
+
+// adoptOption applies opt to c, dropping any process it registers under a
+// name c already uses. The caller must hold c.mu.
+func (c *Conductor) adoptOption(opt Option) {
+	before := len(c.entries)
+	opt(c)
+
+	kept := c.entries[:before]
+	for _, e := range c.entries[before:] {
+		if c.find(e.name()) != e {
+			continue
+		}
+
+		e.builtin = true
+		kept = append(kept, e)
+	}
+
+	c.entries = kept
+	c.options = append(c.options, opt)
+}
@@ -2,13 +2,25 @@ package parallel
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
-	"os/signal"
+	"runtime/pprof"
+	"slices"
 	"sync"
-	"syscall"
+	"sync/atomic"
 	"time"
+)
 
-	"github.com/rs/zerolog"
+var (
+	ErrAlreadyRunning  = errors.New("conductor is already running")
+	ErrAlreadyStopping = errors.New("conductor is already waiting to stop")
+	ErrNotRunning      = errors.New("conductor is not running")
+	ErrProcessBusy     = errors.New("process is already being restarted or stopped")
+	ErrStopped         = errors.New("conductor has been stopped")
+	ErrUnknownProcess  = errors.New("unknown process")
+	ErrProcessSkipped  = errors.New("process is skipped")
 )
 
 type Process interface {
@@ -17,106 +29,520 @@ type Process interface {
 	Name() string
 }
 
-type processError struct {
-	process Process
-	err     error
+type state int
+
+const (
+	stateIdle state = iota
+	stateRunning
+	stateStopping
+	stateStopped
+)
+
+func (s state) String() string {
+	switch s {
+	case stateIdle:
+		return "idle"
+	case stateRunning:
+		return "running"
+	case stateStopping:
+		return "stopping"
+	case stateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
 }
 
 type Conductor struct {
-	log       zerolog.Logger
-	stop      chan os.Signal
-	errors    chan processError
-	processes []Process
+	log         *slog.Logger
+	metrics     MetricsSink
+	stop        chan os.Signal
+	errors      *errorStream
+	entries     []*entry
+	middleware  [][]Middleware
+	listeners   []func(Event)
+	subscribers map[chan Event]struct{}
+	reporters   []Reporter
+	signals     map[os.Signal]ShutdownPolicy
+	bulkheads   map[string]Bulkhead
+	quarantine  *Quarantine
+	dedup       *errorDedup
+	store       StateStore
+	profiles    []string
+	only        []string
+	skipList    []string
+	options     []Option
+	sigsrc      signalSource
+
+	// integrations names options that are otherwise only visible as
+	// listeners, for the startup summary.
+	integrations []string
+	reportTarget string
+	configDigest string
+
+	runHooks    []func() (func(), error)
+	baseContext func() context.Context
+	cleanups    []func()
+
+	contextOnly   bool
+	adopted       bool
+	stopUnstarted bool
+	stopCompleted bool
+	detectLeaks   bool
+	leakGrace     time.Duration
+	maxRuntime    time.Duration
+
+	notifications inflight
+	runs          inflight
+	isolation     sync.Mutex
+	draining      atomic.Bool
+	shutdownHooks []func(cause error)
+	registrations []*registration
+	history       stopHistory
+	saving        sync.Mutex
+
+	mu      sync.Mutex
+	state   state
+	waiting bool
+	misuse  error
+	ctx     context.Context
+	done    chan struct{}
+	startup *startupTracker
+	reason  string
+	cause   error
+	skipped map[*entry]string
+	leaks   []Leak
+
+	groupRestarts map[string]int
+	groups        map[string]*groupScope
+	bus           *Bus
+	shared        *Shared
+	flights       *Singleflight
+	startRate     *startLimiter
+	shuffle       bool
+	shuffleSeed   uint64
+	desired       *DesiredState
+	auditSink     AuditSink
+	auditLog      []AuditRecord
+	auth          Authenticator
+	flushes       []func(ctx context.Context) error
+	crashDir      string
+	candidates    map[*candidate]struct{}
 }
 
 func NewConductor(processes ...Process) *Conductor {
-	log := zerolog.New(os.Stdout).With().Str("engine", "conductor").Logger()
-	log.Info().Msg("initializing conductor engine")
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil)).With("engine", "conductor")
+	log.Info("initializing conductor engine")
 
 	r := &Conductor{
-		log:       log,
-		stop:      make(chan os.Signal),
-		errors:    make(chan processError, len(processes)),
-		processes: processes,
+		log:     log,
+		metrics: nopSink{},
+		sigsrc:  osSignals{},
+		errors:  newErrorStream(len(processes)),
+	}
+
+	for _, p := range processes {
+		r.entries = append(r.entries, newEntry(p))
 	}
 
-	signal.Notify(r.stop, syscall.SIGINT, syscall.SIGTERM)
+	r.listeners = append(r.listeners, r.recordEvent, r.history.record)
+
 	return r
 }
 
+// Add registers additional processes. Processes added while the conductor
+// is running are started immediately.
+func (c *Conductor) Add(processes ...Process) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == stateStopping {
+		return ErrStopped
+	}
+
+	registered := len(c.entries)
+	for _, p := range processes {
+		c.register(p)
+	}
+
+	if err := c.validate(); err != nil {
+		c.entries = c.entries[:registered]
+		return err
+	}
+
+	if c.state == stateRunning {
+		for _, e := range c.entries[registered:] {
+			e.resetReady()
+			c.launch(c.ctx, e)
+		}
+	}
+
+	return nil
+}
+
 func (c *Conductor) Run(ctx context.Context) *Conductor {
-	go c.monitor(ctx)
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	for _, p := range c.processes {
-		go func(process Process) {
-			c.log.Info().
-				Str("process", process.Name()).
-				Msg("starting process")
+	switch {
+	case c.adopted:
+		c.misuse = ErrAdopted
+	case c.state == stateRunning:
+		c.misuse = ErrAlreadyRunning
+	case c.state == stateStopping:
+		c.misuse = ErrStopped
+	}
 
-			if err := process.Run(ctx); err != nil {
-				c.errors <- processError{
-					process: process,
-					err:     err,
-				}
+	if c.misuse == nil {
+		c.misuse = c.validate()
+	}
 
-				return
-			}
-		}(p)
+	if c.misuse == nil {
+		c.misuse = c.runRunHooks()
+	}
+
+	if c.misuse != nil {
+		c.log.Error("refusing to run conductor", "error", c.misuse)
+		return c
+	}
+
+	if c.state == stateStopped {
+		c.errors = newErrorStream(len(c.entries))
+	}
+
+	ctx = c.runContext(ctx)
+
+	c.state = stateRunning
+	c.ctx = ctx
+	c.stop = make(chan os.Signal, 1)
+	c.done = make(chan struct{})
+	c.startup = newStartupTracker()
+	c.reason = ""
+	c.cause = nil
+	c.groupRestarts = nil
+	c.groups = nil
+	c.skipped = nil
+	c.draining.Store(false)
+	c.checkFilter()
+
+	if !c.contextOnly {
+		c.sigsrc.Notify(c.stop, c.shutdownSignals()...)
+		c.relay(ctx, c.done)
+	}
+
+	go c.monitor(ctx, c.errors, c.done)
+	if c.maxRuntime > 0 {
+		go c.enforceMaxRuntime(c.maxRuntime, c.done)
+	}
+	published.Store(c)
+
+	for _, e := range c.entries {
+		e.resetReady()
+		e.clearQuarantine()
+		e.setHalted(false)
+	}
+
+	c.restoreState()
+	c.applyDesiredState()
+
+	for _, e := range c.startOrder() {
+		c.launch(ctx, e)
 	}
 
 	return c
 }
 
-func (c *Conductor) ThenStop() {
-	<-c.stop
-	c.log.Warn().Msg("received stop signal, stopping all processes")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	var wg sync.WaitGroup
-	for _, p := range c.processes {
-		wg.Add(1)
-		go func(process Process) {
-			defer wg.Done()
-
-			if err := process.Stop(ctx); err != nil {
-				c.log.Error().
-					Str("process", process.Name()).
-					Err(err).
-					Msg("failed to stop process")
-			} else {
-				c.log.Info().
-					Str("process", process.Name()).
-					Msg("stopped process")
-			}
-		}(p)
+// start runs e in its own goroutine, recording its readiness in tracker
+// when it is part of startup. The caller must hold c.mu.
+func (c *Conductor) start(ctx context.Context, e *entry, tracker *startupTracker) {
+	c.startWith(ctx, e, tracker, c.errors)
+}
+
+// startWith is start reporting the failures of e to errs.
+func (c *Conductor) startWith(ctx context.Context, e *entry, tracker *startupTracker, errs *errorStream) {
+	done := make(chan struct{})
+
+	if group := groupOf(e.process); group != "" {
+		ctx = c.groupContext(ctx, group)
 	}
 
-	wg.Wait()
-	signal.Stop(c.stop)
+	ctx = context.WithValue(ctx, conductorKey{}, c)
+
+	e.setState(ProcessStarting)
+
+	e.mu.Lock()
+	e.done = done
+	e.mu.Unlock()
+
+	c.runs.add()
+	go func() {
+		defer c.runs.done()
+		defer close(done)
+		defer c.crashGuard("process supervision")
+
+		process := e.process
+		c.waitStartTurn(ctx, process.Name())
+		if err := c.restore(e); err != nil {
+			c.fail(ctx, e, OpRestore, err, errs)
+			return
+		}
+
+		c.log.Info("starting process", "process", process.Name())
+
+		var kv []string
+		for k, v := range labels(process) {
+			kv = append(kv, k, v)
+		}
+
+		pprof.Do(ctx, pprof.Labels(kv...), func(ctx context.Context) {
+			go c.awaitReady(ctx, tracker, e, time.Now(), errs)
+
+			c.emit(Event{Type: EventProcessStarted, Process: process.Name()})
+
+			c.finish(ctx, e, runRecovered(ctx, process), errs)
+		})
+	}()
 }
 
-func (c *Conductor) Errors() <-chan processError {
-	return c.errors
+type conductorKey struct{}
+
+// conductorFrom returns the conductor running the process whose Run was
+// given ctx, or nil.
+func conductorFrom(ctx context.Context) *Conductor {
+	c, _ := ctx.Value(conductorKey{}).(*Conductor)
+	return c
 }
 
-func (c *Conductor) monitor(ctx context.Context) {
-	select {
-	case err := <-c.errors:
-		if err.err != nil {
-			c.log.Error().
-				Str("process", err.process.Name()).
-				Err(err.err).
-				Msg("process error")
+// finish handles the return of e's Run with err.
+func (c *Conductor) finish(ctx context.Context, e *entry, err error, errs *errorStream) {
+	switch e.consumeInterrupt() {
+	case interruptRestart:
+		return
+	case interruptStop:
+		e.setState(ProcessStopped)
+		return
+	}
+
+	if err != nil {
+		c.fail(ctx, e, OpRun, err, errs)
+		return
+	}
+
+	if ctx.Err() != nil {
+		e.transition(ProcessStopped, ProcessStarting, ProcessRunning)
+		return
+	}
+
+	e.transition(ProcessExited, ProcessStarting, ProcessRunning)
+	c.emit(Event{Type: EventProcessExited, Process: e.name()})
+}
+
+// ThenStop blocks until a stop signal, process error, or context
+// cancellation, then stops all processes. It returns an error if the
+// conductor was never run or if Run was misused along the way.
+func (c *Conductor) ThenStop() error {
+	defer c.crashGuard("shutdown")
+
+	c.mu.Lock()
+	switch {
+	case c.state == stateIdle, c.state == stateStopped:
+		err := c.misuse
+		if err == nil {
+			err = ErrNotRunning
+		}
+
+		c.misuse = nil
+		c.mu.Unlock()
+		return err
+	case c.waiting:
+		c.mu.Unlock()
+		return ErrAlreadyStopping
+	}
+
+	c.waiting = true
+	stop, done := c.stop, c.done
+	c.mu.Unlock()
+
+	sig := <-stop
+	c.draining.Store(true)
+	close(done)
+
+	c.mu.Lock()
+	errs := c.errors
+	c.mu.Unlock()
+	errs.stopping()
+
+	c.mu.Lock()
+	policy := c.policy(sig)
+	signaled := c.reason == ""
+	if signaled {
+		c.reason = "signal: " + sig.String()
+		if policy.Name != "" {
+			c.reason += " (" + policy.Name + ")"
+		}
+
+		c.cause = errors.New(c.reason)
+	}
+
+	c.state = stateStopping
+	levels := c.stopLevels()
+	reason, cause := c.reason, c.cause
+	hooks := c.shutdownHooks
+	candidates := c.candidates
+	c.candidates = nil
+	c.mu.Unlock()
+
+	if signaled {
+		c.audit(WithActor(context.Background(), "signal"), "shutdown", sig.String(), nil)
+	}
+
+	force, forced := context.WithCancel(context.Background())
+	defer forced()
+
+	finished := make(chan struct{})
+	defer close(finished)
+	go c.watchSecondSignal(stop, finished, forced)
+
+	for _, hook := range hooks {
+		hook(cause)
+	}
+
+	c.deregisterServices(policy.Timeout)
+
+	c.log.Warn("received stop signal, stopping all processes", "reason", reason, "timeout", policy.Timeout)
+	c.emit(Event{Type: EventShutdownStarted})
+
+	c.abandonAll(candidates)
+
+	started := time.Now()
+	results := c.stopAll(force, levels, policy, cause)
+	running := c.awaitRuns(force, levels, started.Add(policy.Timeout))
+
+	summary := make([]any, len(results))
+	for i, r := range results {
+		switch {
+		case r.completed:
+			summary[i] = slog.Group(r.name, "completed", true)
+		case r.unstarted:
+			summary[i] = slog.Group(r.name, "never_started", true)
+		case slices.Contains(running, r.name):
+			summary[i] = slog.Group(r.name, "duration", r.duration, "budget", r.budget, "still_running", true)
+		default:
+			summary[i] = slog.Group(r.name, "duration", r.duration, "budget", r.budget)
 		}
+	}
+
+	duration := time.Since(started)
+	c.log.Info("shutdown complete", "duration", duration, slog.Group("processes", summary...))
+	c.emit(Event{Type: EventShutdownComplete, Duration: duration})
+	<-c.notifications.idle()
+
+	var leaks []Leak
+	if c.detectLeaks {
+		leaks = c.findLeaks(c.Names(), c.leakGrace)
+
+		c.mu.Lock()
+		c.leaks = leaks
+		c.mu.Unlock()
+	}
 
-		c.stop <- syscall.SIGTERM
+	c.summarize(newShutdownSummary(reason, duration, results, running, leaks))
+	c.flush()
+
+	c.sigsrc.Stop(stop)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.state = stateStopped
+	c.waiting = false
+	c.errors.close()
+	c.cancelGroups()
+	c.closeWindows()
+	if c.bus != nil {
+		c.bus.closeAll()
+	}
+
+	if c.flights != nil {
+		c.flights.forget()
+	}
+
+	c.cleanup()
+
+	err := c.misuse
+	c.misuse = nil
+	return err
+}
+
+// Shutdown begins the graceful shutdown of a running conductor, as if it had
+// received SIGTERM, with reason as its shutdown reason. ThenStop returns
+// once the shutdown completes.
+func (c *Conductor) Shutdown(reason string) {
+	c.shutdown(reason, nil)
+}
+
+// shutdown asks a running conductor to begin its graceful shutdown. Only
+// the first reason given during a run is kept, along with its cause, which
+// defaults to an error carrying the reason.
+func (c *Conductor) shutdown(reason string, cause error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != stateRunning {
 		return
-	case <-ctx.Done():
-		c.log.Warn().Msg("context cancelled")
+	}
+
+	if c.reason == "" {
+		if cause == nil {
+			cause = errors.New(reason)
+		}
+
+		c.reason, c.cause = reason, cause
+	}
+
+	select {
+	case c.stop <- sigTerminate:
+	default:
+	}
+}
+
+// Errors returns the error channel of the current run. It is closed when
+// ThenStop returns, and failures after that are only logged. A restarted
+// conductor gets a fresh channel, so call Errors again after each Run.
+func (c *Conductor) Errors() <-chan *Error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.errors.ch
+}
+
+func (c *Conductor) monitor(ctx context.Context, errs *errorStream, done <-chan struct{}) {
+	defer c.crashGuard("monitor")
+
+	for {
+		select {
+		case err := <-errs.ch:
+			if !err.suppressed {
+				c.log.Error("process error", err.logAttrs()...)
+			}
+
+			if c.isolate(err) || c.quarantineFailure(err) {
+				continue
+			}
+
+			c.shutdown(fmt.Sprintf("process %s failed: %v", err.Process, err.Err), err)
+		case <-ctx.Done():
+			cause := context.Cause(ctx)
+			reason := "context cancelled"
+			if cause != ctx.Err() {
+				reason += ": " + cause.Error()
+			}
+
+			c.log.Warn("context cancelled", "cause", cause)
+			c.shutdown(reason, cause)
+		case <-done:
+		}
 
-		c.stop <- syscall.SIGTERM
 		return
 	}
 }
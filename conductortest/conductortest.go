@@ -0,0 +1,29 @@
+// Package conductortest helps integration tests drive a conductor through
+// its real signal handling without sending OS signals to the test binary,
+// which other tests, go test -count and IDE runners would also receive.
+package conductortest
+
+import (
+	"os"
+
+	"github.com/franklad/parallel"
+)
+
+// New returns a conductor for processes that receives only the signals
+// sent with SendSignal.
+func New(processes ...parallel.Process) *parallel.Conductor {
+	return parallel.NewConductor(processes...).With(parallel.WithVirtualSignals())
+}
+
+// SendSignal delivers sig to c as if the OS had sent it, and reports
+// whether c was listening for it. c must come from New or use
+// parallel.WithVirtualSignals; SendSignal panics otherwise, rather than
+// signal the test binary.
+func SendSignal(c *parallel.Conductor, sig os.Signal) bool {
+	delivered, err := c.SendVirtualSignal(sig)
+	if err != nil {
+		panic("conductortest: " + err.Error())
+	}
+
+	return delivered
+}
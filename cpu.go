@@ -0,0 +1,32 @@
+package parallel
+
+import (
+	"math"
+	"runtime"
+)
+
+// CPUs returns the number of CPUs the process can keep busy: GOMAXPROCS,
+// lowered to the cgroup CPU quota, rounded up, when the process runs under
+// one. Inside a container this is the container's share rather than the
+// CPU count of the host.
+func CPUs() int {
+	n := runtime.GOMAXPROCS(0)
+	if quota, ok := cpuQuota(); ok {
+		n = min(n, max(int(math.Ceil(quota)), 1))
+	}
+
+	return n
+}
+
+// WithGOMAXPROCSFromQuota lowers GOMAXPROCS to the cgroup CPU quota, so
+// the runtime does not schedule goroutines on more threads than the
+// container may run at once.
+func WithGOMAXPROCSFromQuota() Option {
+	return func(c *Conductor) {
+		from := runtime.GOMAXPROCS(0)
+		if to := CPUs(); to != from {
+			runtime.GOMAXPROCS(to)
+			c.log.Info("set GOMAXPROCS from CPU quota", "from", from, "to", to)
+		}
+	}
+}
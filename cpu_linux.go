@@ -0,0 +1,100 @@
+package parallel
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cpuQuota returns the CPU quota of the process's cgroup in CPUs, the
+// tightest one of the cgroup and its ancestors.
+func cpuQuota() (float64, bool) {
+	f, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	quota, found := 0.0, false
+	limit := func(q float64, ok bool) {
+		if ok && (!found || q < quota) {
+			quota, found = q, true
+		}
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// hierarchy-ID:controllers:path
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		switch {
+		case fields[1] == "":
+			for dir := fields[2]; ; dir = path.Dir(dir) {
+				limit(cpuMax(path.Join(cgroupRoot, dir, "cpu.max")))
+				if dir == "/" || dir == "." {
+					break
+				}
+			}
+		case slices.Contains(strings.Split(fields[1], ","), "cpu"):
+			// cgroup v1 mounts the hierarchy under the controller names;
+			// inside a container the process's cgroup is usually the root.
+			for _, dir := range []string{path.Join(cgroupRoot, "cpu", fields[2]), path.Join(cgroupRoot, "cpu")} {
+				limit(cfsQuota(dir))
+			}
+		}
+	}
+
+	return quota, found
+}
+
+// cpuMax parses a cgroup v2 cpu.max file, "max 100000" or "50000 100000".
+func cpuMax(file string) (float64, bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return 0, false
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 || fields[0] == "max" {
+		return 0, false
+	}
+
+	return ratio(fields[0], fields[1])
+}
+
+// cfsQuota reads the cgroup v1 CFS quota of dir, where -1 means no quota.
+func cfsQuota(dir string) (float64, bool) {
+	quota, err := os.ReadFile(path.Join(dir, "cpu.cfs_quota_us"))
+	if err != nil {
+		return 0, false
+	}
+
+	period, err := os.ReadFile(path.Join(dir, "cpu.cfs_period_us"))
+	if err != nil {
+		return 0, false
+	}
+
+	return ratio(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+}
+
+func ratio(quota, period string) (float64, bool) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0, false
+	}
+
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0, false
+	}
+
+	return q / p, true
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package parallel
+
+func cpuQuota() (float64, bool) {
+	return 0, false
+}
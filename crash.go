@@ -0,0 +1,119 @@
+package parallel
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// crashExitCode is the exit status after a crash report, EX_SOFTWARE in
+// sysexits.h.
+const crashExitCode = 70
+
+// CrashReport is what WithCrashReport writes about an unrecoverable
+// failure of the conductor itself.
+type CrashReport struct {
+	Reason         string                  `json:"reason"`
+	Time           time.Time               `json:"time"`
+	PID            int                     `json:"pid"`
+	GoVersion      string                  `json:"go_version"`
+	Panic          string                  `json:"panic,omitempty"`
+	Stack          string                  `json:"stack,omitempty"`
+	ShutdownReason string                  `json:"shutdown_reason,omitempty"`
+	Processes      map[string]ProcessState `json:"processes,omitempty"`
+	Goroutines     string                  `json:"goroutines"`
+}
+
+// WithCrashReport puts the conductor in crash-only mode: a panic inside the
+// conductor, as opposed to one in a process, and a second stop signal while
+// the shutdown is in progress write a CrashReport, with the stacks of every
+// goroutine and the state of every process, to a new file in dir, flush
+// the WithFlush sinks and exit with status 70. Without it, panics inside
+// the conductor crash the binary as usual and further stop signals are
+// ignored.
+func WithCrashReport(dir string) Option {
+	return func(c *Conductor) {
+		c.crashDir = dir
+	}
+}
+
+// crashGuard turns a panic into a crash report in crash-only mode. It must
+// be deferred directly so that it can recover.
+func (c *Conductor) crashGuard(where string) {
+	if c.crashDir == "" {
+		return
+	}
+
+	if v := recover(); v != nil {
+		c.crash(fmt.Sprintf("panic in %s", where), v, debug.Stack())
+	}
+}
+
+// crash writes a crash report and exits. It does not wait for locks, since
+// the conductor may have panicked holding them.
+func (c *Conductor) crash(reason string, panicked any, stack []byte) {
+	report := CrashReport{
+		Reason:    reason,
+		Time:      time.Now(),
+		PID:       os.Getpid(),
+		GoVersion: runtime.Version(),
+		Stack:     string(stack),
+	}
+
+	if panicked != nil {
+		report.Panic = fmt.Sprint(panicked)
+	}
+
+	if c.mu.TryLock() {
+		report.ShutdownReason = c.reason
+		report.Processes = make(map[string]ProcessState, len(c.entries))
+		for _, e := range c.entries {
+			state := ProcessState("unknown")
+			if e.mu.TryLock() {
+				state = e.state
+				e.mu.Unlock()
+			}
+
+			report.Processes[e.name()] = state
+		}
+		c.mu.Unlock()
+	}
+
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= 64<<20 {
+			report.Goroutines = string(buf[:n])
+			break
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+
+	path := filepath.Join(c.crashDir, fmt.Sprintf("crash-%s-%d.json", report.Time.UTC().Format("20060102T150405Z"), report.PID))
+	if err := writeCrashReport(path, report); err != nil {
+		c.log.Error("conductor crashed, failed to write crash report", "reason", reason, "panic", report.Panic, "error", err)
+	} else {
+		c.log.Error("conductor crashed", "reason", reason, "panic", report.Panic, "report", path)
+	}
+
+	c.runFlushes(c.flushes)
+	os.Exit(crashExitCode)
+}
+
+func writeCrashReport(path string, report CrashReport) error {
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b, 0o600)
+}
@@ -0,0 +1,101 @@
+package parallel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+var ErrInstanceLocked = errors.New("another instance holds the lock")
+
+// WithPIDFile writes the process ID to path when the conductor runs and
+// removes the file again once shutdown completes, unless another process
+// has replaced it in the meantime. The file is removed with the privileges
+// the process has at shutdown, so with WithPrivilegeDrop it must be in a
+// directory the unprivileged user can write to. A failed removal is logged.
+func WithPIDFile(path string) Option {
+	return func(c *Conductor) {
+		pid := []byte(strconv.Itoa(os.Getpid()) + "\n")
+
+		c.onRun(func() (func(), error) {
+			if err := os.WriteFile(path, pid, 0o644); err != nil {
+				return nil, fmt.Errorf("write pid file: %w", err)
+			}
+
+			return func() {
+				if b, err := os.ReadFile(path); err == nil && bytes.Equal(b, pid) {
+					if err := os.Remove(path); err != nil {
+						c.log.Warn("failed to remove pid file", "path", path, "error", err)
+					}
+				}
+			}, nil
+		})
+	}
+}
+
+// WithInstanceLock takes an exclusive lock on path when the conductor runs,
+// so a second instance of the service refuses to start with
+// ErrInstanceLocked. The lock is released once shutdown completes. The
+// file is left in place, since removing it would let two instances hold
+// locks on different files.
+func WithInstanceLock(path string) Option {
+	return func(c *Conductor) {
+		c.onRun(func() (func(), error) {
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+			if err != nil {
+				return nil, fmt.Errorf("open lock file: %w", err)
+			}
+
+			if err := lockFile(f); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("%w: %s: %v", ErrInstanceLocked, path, err)
+			}
+
+			if err := f.Truncate(0); err == nil {
+				f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+			}
+
+			return func() {
+				unlockFile(f)
+				f.Close()
+			}, nil
+		})
+	}
+}
+
+// onRun registers hook to be called by Run before any process starts. A
+// hook error refuses the run; the cleanup it returns is called once
+// shutdown completes.
+func (c *Conductor) onRun(hook func() (cleanup func(), err error)) {
+	c.runHooks = append(c.runHooks, hook)
+}
+
+// runRunHooks calls the run hooks, undoing the ones that succeeded if a
+// later one fails. The caller must hold c.mu.
+func (c *Conductor) runRunHooks() error {
+	for _, hook := range c.runHooks {
+		cleanup, err := hook()
+		if err != nil {
+			c.cleanup()
+			return err
+		}
+
+		if cleanup != nil {
+			c.cleanups = append(c.cleanups, cleanup)
+		}
+	}
+
+	return nil
+}
+
+// cleanup calls the cleanups of the run hooks in reverse order. The caller
+// must hold c.mu.
+func (c *Conductor) cleanup() {
+	for i := len(c.cleanups) - 1; i >= 0; i-- {
+		c.cleanups[i]()
+	}
+
+	c.cleanups = nil
+}
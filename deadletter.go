@@ -0,0 +1,181 @@
+package parallel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const deadLetterTimeout = 10 * time.Second
+
+// DeadLetter is a pool job that failed on every attempt. Err joins the
+// errors of all attempts in order, so errors.Is and errors.As see each of
+// them, and Payload is the value given with the Payload submit option.
+type DeadLetter struct {
+	Pool      string
+	Job       JobID
+	Payload   any
+	Attempts  int
+	Err       error
+	Submitted time.Time
+	Failed    time.Time
+}
+
+// MarshalJSON writes Err as a list of the messages of the attempts.
+func (d DeadLetter) MarshalJSON() ([]byte, error) {
+	var errs []string
+	if j, ok := d.Err.(interface{ Unwrap() []error }); ok {
+		for _, err := range j.Unwrap() {
+			errs = append(errs, err.Error())
+		}
+	} else if d.Err != nil {
+		errs = append(errs, d.Err.Error())
+	}
+
+	return json.Marshal(struct {
+		Pool      string    `json:"pool"`
+		Job       JobID     `json:"job"`
+		Payload   any       `json:"payload,omitempty"`
+		Attempts  int       `json:"attempts"`
+		Errors    []string  `json:"errors"`
+		Submitted time.Time `json:"submitted"`
+		Failed    time.Time `json:"failed"`
+	}{d.Pool, d.Job, d.Payload, d.Attempts, errs, d.Submitted, d.Failed})
+}
+
+// DeadLetterHandler receives the pool jobs that exhausted their retries.
+type DeadLetterHandler interface {
+	HandleDeadLetter(ctx context.Context, d DeadLetter) error
+}
+
+// DeadLetterFunc adapts a function to a DeadLetterHandler.
+type DeadLetterFunc func(ctx context.Context, d DeadLetter) error
+
+func (f DeadLetterFunc) HandleDeadLetter(ctx context.Context, d DeadLetter) error {
+	return f(ctx, d)
+}
+
+// DeadLetterChan returns a DeadLetterHandler sending dead letters to ch,
+// waiting for the receiver for as long as the handler's context allows.
+func DeadLetterChan(ch chan<- DeadLetter) DeadLetterHandler {
+	return DeadLetterFunc(func(ctx context.Context, d DeadLetter) error {
+		select {
+		case ch <- d:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// FileDeadLetters is a DeadLetterHandler appending each dead letter to the
+// file at Path as a JSON line, for inspection or replay. Payloads must be
+// encodable as JSON.
+type FileDeadLetters struct {
+	Path string
+}
+
+func (f FileDeadLetters) HandleDeadLetter(ctx context.Context, d DeadLetter) error {
+	line, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Payload attaches v to the job, to be handed to the dead-letter handler
+// if the job exhausts its retries.
+func Payload(v any) SubmitOption {
+	return func(q *queuedJob) {
+		q.payload = v
+	}
+}
+
+// Retries sets how many times the job is retried after failing, instead of
+// PoolOptions.Retries.
+func Retries(n int) SubmitOption {
+	return func(q *queuedJob) {
+		q.retries = max(n, 0)
+	}
+}
+
+// attempt runs the job of q until it succeeds, has been retried q.retries
+// times or its context is done, waiting between attempts with a backoff
+// that doubles from PoolOptions.RetryBackoff. It returns the number of
+// attempts and the errors of the failed ones joined.
+func (p *Pool) attempt(ctx context.Context, q queuedJob) (int, error) {
+	var errs []error
+	backoff := p.opts.RetryBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := runJob(ctx, q.job)
+		if err == nil {
+			return attempt, nil
+		}
+
+		errs = append(errs, err)
+		if attempt > q.retries || ctx.Err() != nil {
+			return attempt, errors.Join(errs...)
+		}
+
+		p.sink().Count("pool.retries", 1, p.tags(q.priority)...)
+
+		t := time.NewTimer(backoff)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return attempt, errors.Join(errs...)
+		}
+
+		backoff *= 2
+	}
+}
+
+// deadLetter hands the failed job of q to the dead-letter handler, logging
+// it through the conductor running the pool when there is none or the
+// handler fails.
+func (p *Pool) deadLetter(ctx context.Context, q queuedJob, err error, attempts int) {
+	d := DeadLetter{
+		Pool:      p.name,
+		Job:       q.record.status.ID,
+		Payload:   q.payload,
+		Attempts:  attempts,
+		Err:       err,
+		Submitted: q.submitted,
+		Failed:    time.Now(),
+	}
+
+	p.sink().Count("pool.dead_letters", 1, p.tags(q.priority)...)
+
+	handler := p.opts.DeadLetters
+	if handler != nil {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deadLetterTimeout)
+		defer cancel()
+
+		herr := handler.HandleDeadLetter(ctx, d)
+		if herr == nil {
+			return
+		}
+
+		err = errors.Join(err, fmt.Errorf("dead-letter handler: %w", herr))
+	}
+
+	if c := p.conductor.Load(); c != nil {
+		c.log.Error("pool job failed", "process", p.name, "job", d.Job, "attempts", attempts, "error", err)
+	}
+}
@@ -0,0 +1,67 @@
+package parallel
+
+import (
+	"sync"
+	"time"
+)
+
+// errorDedup limits how often the failures of each process are logged,
+// emitted and reported.
+type errorDedup struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	processes map[string]*dedupState
+}
+
+type dedupState struct {
+	last       string
+	reported   time.Time
+	suppressed int
+}
+
+// WithErrorDedup reports the same failure of a process at most once every
+// window, so a crash loop or a failing poll does not flood logs, error
+// reporters and notifiers with identical messages. Repeats are still
+// handled as usual, restarting or quarantining the process or shutting the
+// conductor down, but are only counted; the next failure reported carries
+// the count as "repeated" in its log entry and as Event.Repeated.
+func WithErrorDedup(window time.Duration) Option {
+	return func(c *Conductor) {
+		c.dedup = &errorDedup{
+			window:    window,
+			processes: make(map[string]*dedupState),
+		}
+	}
+}
+
+// admit reports whether err should be reported and, if so, how many repeats
+// of the previous failure of its process were suppressed. A failure with a
+// different message always gets through, so a new problem is never hidden
+// behind an old one.
+func (d *errorDedup) admit(err *Error) (repeated int, ok bool) {
+	if d == nil {
+		return 0, true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	msg := err.Err.Error()
+
+	s, found := d.processes[err.Process]
+	if !found {
+		s = &dedupState{}
+		d.processes[err.Process] = s
+	}
+
+	if found && msg == s.last && now.Sub(s.reported) < d.window {
+		s.suppressed++
+		return 0, false
+	}
+
+	repeated = s.suppressed
+	s.last, s.reported, s.suppressed = msg, now, 0
+	return repeated, true
+}
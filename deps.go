@@ -0,0 +1,284 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownDependency = errors.New("unknown dependency")
+	ErrDependencyCycle   = errors.New("dependency cycle")
+)
+
+// Dependent is implemented by processes that must not start until the named
+// processes are ready. During shutdown a process is stopped before the
+// processes it depends on.
+type Dependent interface {
+	DependsOn() []string
+}
+
+type dependentProcess struct {
+	Process
+	deps []string
+}
+
+// After declares that p depends on the named processes.
+func After(p Process, deps ...string) Process {
+	return &dependentProcess{
+		Process: p,
+		deps:    deps,
+	}
+}
+
+func (d *dependentProcess) DependsOn() []string {
+	return d.deps
+}
+
+func (d *dependentProcess) Unwrap() Process {
+	return d.Process
+}
+
+func dependsOn(p Process) []string {
+	if d, ok := as[Dependent](p); ok {
+		return d.DependsOn()
+	}
+
+	return nil
+}
+
+// Edge means From must be ready before To is started.
+type Edge struct {
+	From string
+	To   string
+}
+
+type Graph struct {
+	Nodes []string
+	Edges []Edge
+}
+
+// Graph returns the startup topology of the registered processes.
+func (c *Conductor) Graph() *Graph {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g := &Graph{}
+	for _, e := range c.entries {
+		g.Nodes = append(g.Nodes, e.name())
+		for _, dep := range dependsOn(e.process) {
+			g.Edges = append(g.Edges, Edge{From: dep, To: e.name()})
+		}
+	}
+
+	return g
+}
+
+// WriteDOT renders the graph in Graphviz DOT format.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph parallel {\n")
+
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "\t%s;\n", strconv.Quote(n))
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "\t%s -> %s;\n", strconv.Quote(e.From), strconv.Quote(e.To))
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Validate checks that every dependency refers to a registered process and
+// that the dependencies contain no cycles.
+func (c *Conductor) Validate() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.validate()
+}
+
+func (c *Conductor) validate() error {
+	for _, e := range c.entries {
+		for _, dep := range dependsOn(e.process) {
+			if c.find(dep) == nil {
+				return fmt.Errorf("%w: %s depends on %s", ErrUnknownDependency, e.name(), dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	marks := make(map[*entry]int, len(c.entries))
+
+	var (
+		path  []string
+		visit func(e *entry) error
+	)
+
+	visit = func(e *entry) error {
+		switch marks[e] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(append(path, e.name()), " -> "))
+		}
+
+		marks[e] = visiting
+		path = append(path, e.name())
+
+		for _, dep := range dependsOn(e.process) {
+			if err := visit(c.find(dep)); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		marks[e] = visited
+		return nil
+	}
+
+	for _, e := range c.entries {
+		if err := visit(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// levels groups entries so that every entry comes after all of its
+// dependencies. The dependencies must already be validated.
+func (c *Conductor) levels() [][]*entry {
+	depth := make(map[*entry]int, len(c.entries))
+
+	var measure func(e *entry) int
+	measure = func(e *entry) int {
+		if d, ok := depth[e]; ok {
+			return d
+		}
+
+		d := 0
+		for _, dep := range dependsOn(e.process) {
+			d = max(d, measure(c.find(dep))+1)
+		}
+
+		depth[e] = d
+		return d
+	}
+
+	var levels [][]*entry
+	for _, e := range c.entries {
+		d := measure(e)
+		for len(levels) <= d {
+			levels = append(levels, nil)
+		}
+
+		levels[d] = append(levels[d], e)
+	}
+
+	return levels
+}
+
+// stopLevels is levels without the processes that are not part of the
+// current run: skipped processes and lazy or windowed processes that are
+// not running.
+// The caller must hold c.mu.
+func (c *Conductor) stopLevels() [][]*entry {
+	var levels [][]*entry
+	for _, level := range c.levels() {
+		level = slices.DeleteFunc(level, func(e *entry) bool {
+			return c.skipped[e] != "" || ((isLazy(e.process) || scheduleOf(e.process) != nil) && !e.running())
+		})
+
+		if len(level) > 0 {
+			levels = append(levels, level)
+		}
+	}
+
+	return levels
+}
+
+// launch starts e once every process it depends on is ready, unless it is
+// skipped, quarantined by a previous instance, kept stopped by LoadState,
+// a lazy process that has not been asked for or a windowed process outside
+// its window. The caller must hold c.mu.
+func (c *Conductor) launch(ctx context.Context, e *entry) {
+	if c.skip(e) || e.isQuarantined() || e.isHalted() {
+		return
+	}
+
+	if isLazy(e.process) {
+		e.mu.Lock()
+		e.done = nil
+		e.mu.Unlock()
+
+		e.setState(ProcessIdle)
+		return
+	}
+
+	if s := scheduleOf(e.process); s != nil && !c.openWindow(e, s) {
+		return
+	}
+
+	c.begin(ctx, e)
+}
+
+// begin is launch without the checks, starting any lazy process e depends
+// on. The caller must hold c.mu.
+func (c *Conductor) begin(ctx context.Context, e *entry) {
+	tracker := c.startup
+	if !tracker.expect() {
+		tracker = nil
+	}
+
+	e.mu.Lock()
+	e.done = nil
+	e.mu.Unlock()
+
+	var deps []*entry
+	for _, name := range dependsOn(e.process) {
+		dep := c.find(name)
+		c.wake(dep)
+		deps = append(deps, dep)
+	}
+
+	if len(deps) == 0 {
+		c.start(ctx, e, tracker)
+		return
+	}
+
+	e.setState(ProcessWaiting)
+
+	done := c.done
+	go func() {
+		for _, dep := range deps {
+			select {
+			case <-dep.readyChan():
+			case <-done:
+				return
+			}
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.state != stateRunning || c.done != done {
+			return
+		}
+
+		c.start(ctx, e, tracker)
+	}()
+}
@@ -0,0 +1,138 @@
+package parallel
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// DesiredState is the state of the processes adjusted by operators at run
+// time, which SaveState writes and LoadState reads back, keyed by process
+// name.
+type DesiredState struct {
+	Processes map[string]DesiredProcess `json:"processes"`
+}
+
+// DesiredProcess is the operator-adjusted state of a process: whether it
+// was stopped through a Handle or the admin API, whether it is quarantined
+// and, for a Pool, the number of workers it is sized to.
+type DesiredProcess struct {
+	Stopped     bool `json:"stopped,omitempty"`
+	Quarantined bool `json:"quarantined,omitempty"`
+	Workers     int  `json:"workers,omitempty"`
+}
+
+// SaveState writes the desired state of every process to w as JSON, so a
+// restarted binary can resume it with LoadState instead of reverting to
+// the defaults in code. It can be called while the conductor runs or after
+// ThenStop returns.
+func (c *Conductor) SaveState(w io.Writer) error {
+	c.mu.Lock()
+	entries := c.entries
+	c.mu.Unlock()
+
+	state := DesiredState{Processes: make(map[string]DesiredProcess, len(entries))}
+	for _, e := range entries {
+		e.mu.Lock()
+		d := DesiredProcess{Stopped: e.halted, Quarantined: e.quarantined}
+		e.mu.Unlock()
+
+		if p, ok := as[*Pool](e.process); ok {
+			d.Workers = int(p.size.Load())
+		}
+
+		state.Processes[e.name()] = d
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(state)
+}
+
+// LoadState reads a desired state written by SaveState from r, to be
+// applied by the next Run: stopped processes are not started, quarantined
+// ones stay quarantined and pools start with their saved number of
+// workers. Processes missing from the state keep their defaults, and
+// unknown processes are ignored. It fails with ErrAlreadyRunning while the
+// conductor runs.
+func (c *Conductor) LoadState(r io.Reader) error {
+	var state DesiredState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state == stateRunning || c.state == stateStopping {
+		return ErrAlreadyRunning
+	}
+
+	c.desired = &state
+	return nil
+}
+
+// applyDesiredState applies the state given to LoadState to this run. The
+// caller must hold c.mu.
+func (c *Conductor) applyDesiredState() {
+	state := c.desired
+	c.desired = nil
+	if state == nil {
+		return
+	}
+
+	for _, e := range c.entries {
+		d, ok := state.Processes[e.name()]
+		if !ok {
+			continue
+		}
+
+		if p, ok := as[*Pool](e.process); ok && d.Workers > 0 {
+			p.resize(d.Workers)
+		}
+
+		switch {
+		case d.Quarantined && !e.isQuarantined():
+			c.requarantine(e)
+		case d.Stopped:
+			done := make(chan struct{})
+			close(done)
+
+			e.mu.Lock()
+			e.done = done
+			e.halted = true
+			e.state = ProcessStopped
+			e.mu.Unlock()
+
+			c.log.Info("process stays stopped", "process", e.name())
+		}
+	}
+}
+
+// halt stops e on an operator's request, recording that it should stay
+// stopped in the desired state.
+func (c *Conductor) halt(ctx context.Context, e *entry) error {
+	if err := c.stopProcess(ctx, e); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.halted = true
+	e.mu.Unlock()
+
+	return nil
+}
+
+func (e *entry) setHalted(halted bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.halted = halted
+}
+
+func (e *entry) isHalted() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.halted
+}
@@ -0,0 +1,190 @@
+package parallel
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const devReloadInterval = 500 * time.Millisecond
+
+// DevReloader runs a Command and restarts it whenever a file matching one
+// of its globs changes, running Build first when it is set. If the command
+// exits or the build fails, DevReloader waits for the next change instead
+// of giving up, which makes a conductor usable as a local dev runner.
+type DevReloader struct {
+	Command *Command
+	Globs   []string
+	// Build is run before every start, for example
+	// []string{"go", "build", "-o", "bin/app", "."}.
+	Build    []string
+	Interval time.Duration
+	Log      *slog.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// DevReload returns a DevReloader for cmd watching watchGlobs. Globs are
+// matched with filepath.Match, and a "**" element matches any number of
+// directories, as in "internal/**/*.go".
+func DevReload(cmd *Command, watchGlobs ...string) *DevReloader {
+	return &DevReloader{
+		Command:  cmd,
+		Globs:    watchGlobs,
+		Interval: devReloadInterval,
+		Log:      slog.Default(),
+	}
+}
+
+func (d *DevReloader) Name() string {
+	return d.Command.Name()
+}
+
+func (d *DevReloader) Unwrap() Process {
+	return d.Command
+}
+
+func (d *DevReloader) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	d.mu.Lock()
+	d.cancel = cancel
+	d.mu.Unlock()
+
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		snapshot := d.snapshot()
+
+		exited := make(chan error, 1)
+		err := d.build(ctx)
+		if err != nil {
+			d.Log.Error("dev reload build failed", "process", d.Name(), "error", err)
+		} else {
+			go func() {
+				exited <- d.Command.Run(ctx)
+			}()
+		}
+
+		running := err == nil
+		for changed := false; !changed; {
+			select {
+			case <-ctx.Done():
+				if running {
+					<-exited
+				}
+
+				return nil
+			case err := <-exited:
+				running = false
+				d.Log.Warn("dev reload process exited, waiting for changes", "process", d.Name(), "error", err)
+			case <-ticker.C:
+				changed = !d.snapshot().equal(snapshot)
+			}
+		}
+
+		d.Log.Info("files changed, reloading", "process", d.Name())
+
+		if running {
+			stopCtx, stop := context.WithTimeout(ctx, shutdownTimeout)
+			d.Command.Stop(stopCtx)
+			stop()
+			<-exited
+		}
+	}
+}
+
+func (d *DevReloader) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	cancel := d.cancel
+	d.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+
+	return d.Command.Stop(ctx)
+}
+
+func (d *DevReloader) build(ctx context.Context) error {
+	if len(d.Build) == 0 {
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, d.Build[0], d.Build[1:]...)
+	cmd.Dir = d.Command.Dir
+	cmd.Stdout = d.Command.Stdout
+	cmd.Stderr = d.Command.Stderr
+
+	return cmd.Run()
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+type fileSnapshot map[string]fileState
+
+func (s fileSnapshot) equal(other fileSnapshot) bool {
+	if len(s) != len(other) {
+		return false
+	}
+
+	for path, state := range s {
+		if other[path] != state {
+			return false
+		}
+	}
+
+	return true
+}
+
+// snapshot records the size and modification time of every watched file.
+func (d *DevReloader) snapshot() fileSnapshot {
+	s := make(fileSnapshot)
+	add := func(path string) {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			s[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+		}
+	}
+
+	for _, glob := range d.Globs {
+		root, rest, recursive := strings.Cut(filepath.ToSlash(glob), "**/")
+		if !recursive {
+			matches, _ := filepath.Glob(glob)
+			for _, m := range matches {
+				add(m)
+			}
+
+			continue
+		}
+
+		if root == "" {
+			root = "."
+		}
+
+		filepath.WalkDir(filepath.FromSlash(root), func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return nil
+			}
+
+			if ok, _ := filepath.Match(rest, entry.Name()); ok {
+				add(path)
+			}
+
+			return nil
+		})
+	}
+
+	return s
+}
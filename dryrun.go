@@ -0,0 +1,73 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrDuplicateProcess = errors.New("duplicate process name")
+	ErrInvalidTimeout   = errors.New("invalid timeout")
+)
+
+// DryRun checks the configuration without starting anything: duplicate
+// process names, unknown dependencies, dependency cycles, and non-positive
+// run timeouts. It logs the planned start order and returns every problem
+// found.
+func (c *Conductor) DryRun(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var errs []error
+
+	seen := make(map[string]bool, len(c.entries))
+	for _, e := range c.entries {
+		if seen[e.name()] {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrDuplicateProcess, e.name()))
+		}
+
+		seen[e.name()] = true
+
+		for p := e.process; p != nil; {
+			if t, ok := p.(*timeoutProcess); ok && t.timeout <= 0 {
+				errs = append(errs, fmt.Errorf("%w: %s has run timeout %s", ErrInvalidTimeout, e.name(), t.timeout))
+			}
+
+			if t, ok := p.(*stopTimeoutProcess); ok && t.timeout <= 0 {
+				errs = append(errs, fmt.Errorf("%w: %s has stop timeout %s", ErrInvalidTimeout, e.name(), t.timeout))
+			}
+
+			u, ok := p.(interface{ Unwrap() Process })
+			if !ok {
+				break
+			}
+
+			p = u.Unwrap()
+		}
+	}
+
+	if err := c.validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		c.log.Error("dry run found configuration problems", "error", err)
+		return err
+	}
+
+	for i, level := range c.levels() {
+		names := make([]string, len(level))
+		for j, e := range level {
+			names[j] = e.name()
+		}
+
+		c.log.Info("planned start order", "step", i+1, "processes", names)
+	}
+
+	return nil
+}
@@ -0,0 +1,335 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type ProcessState string
+
+const (
+	ProcessIdle       ProcessState = "idle"
+	ProcessWaiting    ProcessState = "waiting"
+	ProcessStarting   ProcessState = "starting"
+	ProcessRunning    ProcessState = "running"
+	ProcessRestarting ProcessState = "restarting"
+	ProcessStopping   ProcessState = "stopping"
+	ProcessStopped    ProcessState = "stopped"
+	ProcessExited     ProcessState = "exited"
+	ProcessFailed     ProcessState = "failed"
+	ProcessSkipped    ProcessState = "skipped"
+	// ProcessNeverStarted marks a process that was still waiting for its
+	// dependencies when the conductor shut down.
+	ProcessNeverStarted ProcessState = "never_started"
+	// ProcessScheduledOff marks a windowed process outside its time window.
+	ProcessScheduledOff ProcessState = "scheduled_off"
+)
+
+type entry struct {
+	process Process
+
+	// builtin is set for processes registered by an Option, such as the
+	// runtime metrics reporter, rather than by the user.
+	builtin bool
+
+	mu        sync.Mutex
+	state     ProcessState
+	restarts  int
+	done      chan struct{}
+	ready     chan struct{}
+	isReady   bool
+	interrupt interruption
+
+	quarantined bool
+	failures    []time.Time
+	probe       *time.Timer
+
+	usage  ResourceUsage
+	idle   *time.Timer
+	window *time.Timer
+
+	// halted is set for a process stopped by an operator, which stays
+	// stopped in the desired state.
+	halted bool
+
+	// handoff is the state checkpointed by the previous run, for the next.
+	handoff    []byte
+	hasHandoff bool
+}
+
+type interruption int
+
+const (
+	interruptNone interruption = iota
+	interruptRestart
+	interruptStop
+)
+
+func newEntry(p Process) *entry {
+	return &entry{
+		process: p,
+		state:   ProcessIdle,
+	}
+}
+
+// resetReady prepares the entry for a new run of the conductor.
+func (e *entry) resetReady() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.ready = make(chan struct{})
+	e.isReady = false
+}
+
+func (e *entry) markReady() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.isReady {
+		e.isReady = true
+		close(e.ready)
+	}
+}
+
+func (e *entry) readyChan() <-chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.ready
+}
+
+func (e *entry) setState(s ProcessState) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.state = s
+}
+
+// transition moves the entry to s only if it is currently in one of from.
+func (e *entry) transition(s ProcessState, from ...ProcessState) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, f := range from {
+		if e.state == f {
+			e.state = s
+			return
+		}
+	}
+}
+
+// claimStart moves the entry to ProcessWaiting and reports whether it did.
+// It does not if the entry is already waiting, starting or running, so only
+// one caller starts it.
+func (e *entry) claimStart() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	switch e.state {
+	case ProcessWaiting, ProcessStarting, ProcessRunning:
+		return false
+	}
+
+	if e.done != nil && !isClosed(e.done) {
+		return false
+	}
+
+	e.state = ProcessWaiting
+	return true
+}
+
+// started reports whether the entry's Run was started during this run of
+// the conductor.
+func (e *entry) started() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.done != nil
+}
+
+// running reports whether the entry's Run was started and has not returned.
+func (e *entry) running() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.done != nil && !isClosed(e.done)
+}
+
+func (e *entry) status() (ProcessState, int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.state, e.restarts
+}
+
+func (e *entry) name() string {
+	return e.process.Name()
+}
+
+// consumeInterrupt reports why the Run that just returned was stopped on
+// purpose, if it was, clearing the record.
+func (e *entry) consumeInterrupt() interruption {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	i := e.interrupt
+	e.interrupt = interruptNone
+	return i
+}
+
+// register wraps p with the conductor's middleware and appends it to the
+// process set. The caller must hold c.mu.
+func (c *Conductor) register(p Process) *entry {
+	e := newEntry(c.wrap(p))
+	c.entries = append(c.entries, e)
+	return e
+}
+
+func (c *Conductor) lookup(name string) *entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.find(name)
+}
+
+// find is lookup for callers already holding c.mu.
+func (c *Conductor) find(name string) *entry {
+	for _, e := range c.entries {
+		if e.name() == name {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// restart stops a single running process, waits for its Run to return, and
+// runs it again without treating the interruption as a process failure. A
+// process whose Run already returned is simply run again.
+func (c *Conductor) restart(ctx context.Context, e *entry) error {
+	e.mu.Lock()
+	done := e.done
+	if done == nil {
+		e.mu.Unlock()
+		return ErrNotRunning
+	}
+
+	if e.interrupt != interruptNone {
+		e.mu.Unlock()
+		return ErrProcessBusy
+	}
+
+	if e.quarantined {
+		e.mu.Unlock()
+		return ErrQuarantined
+	}
+
+	exited := isClosed(done)
+	if !exited {
+		e.interrupt = interruptRestart
+	}
+
+	state, halted := e.state, e.halted
+	e.restarts++
+	e.halted = false
+	e.state = ProcessRestarting
+	e.mu.Unlock()
+
+	c.log.Info("restarting process", "process", e.name())
+	c.emit(Event{Type: EventProcessRestarting, Process: e.name()})
+
+	if !exited {
+		if err := c.interrupt(ctx, e, done); err != nil {
+			e.mu.Lock()
+			e.restarts--
+			e.halted = halted
+			if e.state == ProcessRestarting {
+				e.state = state
+			}
+			e.mu.Unlock()
+			return err
+		}
+	}
+
+	c.checkpoint(e)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != stateRunning {
+		return ErrNotRunning
+	}
+
+	e.resetReady()
+	c.start(c.ctx, e, nil)
+	return nil
+}
+
+// stopProcess stops a single process without affecting the rest of the
+// conductor and without treating the interruption as a process failure.
+func (c *Conductor) stopProcess(ctx context.Context, e *entry) error {
+	e.mu.Lock()
+	done := e.done
+	switch {
+	case done == nil:
+		e.mu.Unlock()
+		return ErrNotRunning
+	case isClosed(done):
+		e.mu.Unlock()
+		return nil
+	case e.interrupt != interruptNone:
+		e.mu.Unlock()
+		return ErrProcessBusy
+	}
+
+	e.interrupt = interruptStop
+	e.state = ProcessStopping
+	e.mu.Unlock()
+
+	began := time.Now()
+	if err := c.interrupt(ctx, e, done); err != nil {
+		perr := wrapError(OpStop, e.process, err)
+		c.log.Error("failed to stop process", "process", e.name(), "error", err)
+		c.report(ctx, perr)
+		c.emit(Event{Type: EventProcessStopFailed, Process: e.name(), Duration: time.Since(began), Err: perr})
+		return perr
+	}
+
+	c.log.Info("stopped process", "process", e.name())
+	c.emit(Event{Type: EventProcessStopped, Process: e.name(), Duration: time.Since(began)})
+	return nil
+}
+
+// interrupt drains and stops an entry whose interruption is already recorded
+// and waits for its Run to return. Once Stop has succeeded, it keeps waiting
+// after ctx ends, for up to shutdownTimeout, since the Run that consumes
+// the interruption is about to return. If Stop fails or the Run does not
+// return, the interruption is withdrawn.
+func (c *Conductor) interrupt(ctx context.Context, e *entry, done <-chan struct{}) error {
+	if err := c.drainAndStop(ctx, e); err != nil {
+		e.consumeInterrupt()
+		return err
+	}
+
+	timer := time.NewTimer(shutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return nil
+	case <-timer.C:
+		e.consumeInterrupt()
+		return fmt.Errorf("%s did not return within %s of stopping", e.name(), shutdownTimeout)
+	}
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
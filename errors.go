@@ -0,0 +1,207 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sync"
+)
+
+const (
+	OpRun     = "run"
+	OpReady   = "ready"
+	OpWarmup  = "warmup"
+	OpStop    = "stop"
+	OpRestore = "restore"
+)
+
+// Error is how the conductor reports a process failure. Stack is the stack
+// of the panic when the process panicked, and otherwise the stack at which
+// the conductor observed the failure.
+type Error struct {
+	Op      string
+	Process string
+	Labels  map[string]string
+	Class   ErrorClass
+	Err     error
+	Stack   []byte
+
+	// repeated and suppressed are set by WithErrorDedup.
+	repeated   int
+	suppressed bool
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("process %q: %s: %v", e.Process, e.Op, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Fatal reports whether the failure is classified as fatal.
+func (e *Error) Fatal() bool {
+	return e.Class == ClassFatal
+}
+
+// Panic returns the value the process panicked with, or nil if it did not
+// panic.
+func (e *Error) Panic() any {
+	var p *PanicError
+	if errors.As(e.Err, &p) {
+		return p.Value
+	}
+
+	return nil
+}
+
+// Format prints the stack after the message for %+v.
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		fmt.Fprintf(s, "%s\n%s", e.Error(), e.Stack)
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		io.WriteString(s, e.Error())
+	}
+}
+
+// logAttrs returns the attributes used when logging e, including the panic
+// value and stack when the process panicked.
+func (e *Error) logAttrs() []any {
+	attrs := []any{"process", e.Process, "op", e.Op, "error", e.Err}
+	if e.Class == ClassFatal {
+		attrs = append(attrs, "class", e.Class)
+	}
+
+	if e.repeated > 0 {
+		attrs = append(attrs, "repeated", e.repeated)
+	}
+
+	if p := e.Panic(); p != nil {
+		attrs = append(attrs, "panic", fmt.Sprint(p), "stack", string(e.Stack))
+	}
+
+	return attrs
+}
+
+func wrapError(op string, p Process, err error) *Error {
+	var existing *Error
+	if errors.As(err, &existing) && existing.Op == op && existing.Process == p.Name() {
+		return existing
+	}
+
+	e := &Error{
+		Op:      op,
+		Process: p.Name(),
+		Labels:  labels(p),
+		Class:   classify(p, err),
+		Err:     err,
+	}
+
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		e.Stack = panicErr.Stack
+	} else {
+		e.Stack = debug.Stack()
+	}
+
+	return e
+}
+
+// fail records a process failure and delivers it to the monitor.
+func (c *Conductor) fail(ctx context.Context, e *entry, op string, err error, errs *errorStream) {
+	perr := wrapError(op, e.process, err)
+
+	e.transition(ProcessFailed, ProcessStarting, ProcessRunning)
+
+	c.mu.Lock()
+	dedup := c.dedup
+	c.mu.Unlock()
+
+	repeated, ok := dedup.admit(perr)
+	perr.repeated, perr.suppressed = repeated, !ok
+	if !ok {
+		c.sink().Count("process.failures", 1, Tag{Key: "process", Value: perr.Process})
+		errs.send(perr)
+		return
+	}
+
+	c.report(ctx, perr)
+	event := Event{Type: EventProcessFailed, Process: perr.Process, Err: perr, Repeated: repeated}
+	if p := perr.Panic(); p != nil {
+		event.Panic = p
+		event.Stack = perr.Stack
+	}
+
+	c.emit(event)
+	if !errs.send(perr) {
+		c.log.Error("process error after shutdown", perr.logAttrs()...)
+	}
+}
+
+// errorStream owns the error channel of one run. Failures are delivered
+// through send; nobody else writes to the channel or closes it. Once the
+// shutdown begins, sends no longer wait for room in the buffer, and once
+// the stream is closed they are dropped, so a process that fails late
+// never blocks or panics.
+type errorStream struct {
+	ch      chan *Error
+	closing chan struct{}
+	once    sync.Once
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newErrorStream(buffer int) *errorStream {
+	return &errorStream{
+		ch:      make(chan *Error, buffer),
+		closing: make(chan struct{}),
+	}
+}
+
+// send delivers err and reports whether it was, waiting for room in the
+// buffer only until the shutdown begins.
+func (s *errorStream) send(err *Error) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return false
+	}
+
+	select {
+	case s.ch <- err:
+		return true
+	case <-s.closing:
+	}
+
+	select {
+	case s.ch <- err:
+		return true
+	default:
+		return false
+	}
+}
+
+// stopping stops sends from waiting for room in the buffer.
+func (s *errorStream) stopping() {
+	s.once.Do(func() { close(s.closing) })
+}
+
+// close closes the channel. Errors already buffered can still be received.
+func (s *errorStream) close() {
+	s.stopping()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
@@ -0,0 +1,104 @@
+package parallel_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/franklad/parallel"
+	"github.com/franklad/parallel/conductortest"
+)
+
+// lateFailer fails from Run once it is stopped, after delay, and from Stop
+// if stopErr is set.
+type lateFailer struct {
+	name    string
+	delay   time.Duration
+	stopErr error
+	quit    chan struct{}
+}
+
+func newLateFailer(name string, delay time.Duration, stopErr error) *lateFailer {
+	return &lateFailer{name: name, delay: delay, stopErr: stopErr, quit: make(chan struct{})}
+}
+
+func (p *lateFailer) Name() string { return p.name }
+
+func (p *lateFailer) Run(ctx context.Context) error {
+	<-p.quit
+	time.Sleep(p.delay)
+	return errors.New("late failure")
+}
+
+func (p *lateFailer) Stop(ctx context.Context) error {
+	close(p.quit)
+	return p.stopErr
+}
+
+func TestLateFailuresAfterThenStop(t *testing.T) {
+	var processes []parallel.Process
+	for i := range 12 {
+		var stopErr error
+		if i%3 == 0 {
+			stopErr = errors.New("stop failure")
+		}
+
+		// Some fail while the shutdown runs, the rest after ThenStop has
+		// given up waiting for them.
+		delay := time.Duration(i%2) * 300 * time.Millisecond
+		processes = append(processes, newLateFailer(fmt.Sprint("p", i), delay, stopErr))
+	}
+
+	c := conductortest.New(processes...).With(
+		parallel.WithLogger(discard()),
+		parallel.WithShutdownSignal(syscall.SIGTERM, parallel.ShutdownPolicy{Timeout: 100 * time.Millisecond}),
+	)
+	c.Run(context.Background())
+	errs := c.Errors()
+
+	if !conductortest.SendSignal(c, syscall.SIGTERM) {
+		t.Fatal("SIGTERM was not delivered")
+	}
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- c.ThenStop() }()
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatalf("ThenStop: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ThenStop did not return")
+	}
+
+	var received int
+	timeout := time.After(time.Second)
+	for open := true; open; {
+		select {
+		case err, ok := <-errs:
+			if !ok {
+				open = false
+				break
+			}
+
+			if err == nil {
+				t.Fatal("received nil error")
+			}
+
+			received++
+		case <-timeout:
+			t.Fatal("Errors() was not closed")
+		}
+	}
+
+	if received == 0 {
+		t.Error("no buffered errors were drained from Errors()")
+	}
+
+	// Let the processes still running fail after the channel is closed.
+	time.Sleep(400 * time.Millisecond)
+}
@@ -0,0 +1,68 @@
+package parallel
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestErrorStreamSendAfterClose(t *testing.T) {
+	s := newErrorStream(1)
+	s.close()
+	s.close()
+
+	sent := make(chan bool, 1)
+	go func() { sent <- s.send(&Error{Err: errors.New("late")}) }()
+
+	select {
+	case ok := <-sent:
+		if ok {
+			t.Error("send after close reported delivery")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("send after close blocked")
+	}
+}
+
+func TestErrorStreamFullBufferAfterStopping(t *testing.T) {
+	s := newErrorStream(1)
+	if !s.send(&Error{Err: errors.New("first")}) {
+		t.Fatal("send into empty buffer failed")
+	}
+
+	blocked := make(chan bool, 1)
+	go func() { blocked <- s.send(&Error{Err: errors.New("blocked")}) }()
+
+	time.Sleep(50 * time.Millisecond)
+	s.stopping()
+
+	select {
+	case ok := <-blocked:
+		if ok {
+			t.Error("send into full buffer reported delivery")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("send waiting on a full buffer was not released by stopping")
+	}
+
+	sent := make(chan bool, 1)
+	go func() { sent <- s.send(&Error{Err: errors.New("dropped")}) }()
+
+	select {
+	case ok := <-sent:
+		if ok {
+			t.Error("send into full buffer reported delivery")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("send into full buffer blocked after stopping")
+	}
+
+	s.close()
+	if err, ok := <-s.ch; !ok || err.Err.Error() != "first" {
+		t.Errorf("buffered error = %v, %v; want first", err, ok)
+	}
+
+	if _, ok := <-s.ch; ok {
+		t.Error("channel not closed")
+	}
+}
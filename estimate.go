@@ -0,0 +1,173 @@
+package parallel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// stopHistoryWeight is the weight of the latest stop duration in the rolling
+// average of a process's stop durations.
+const stopHistoryWeight = 0.3
+
+type stopSample struct {
+	AverageMS float64 `json:"average_ms"`
+	Samples   int     `json:"samples"`
+}
+
+// stopHistory keeps an exponentially weighted average of how long each
+// process took to stop. It has its own lock so that it can be loaded and
+// saved by run hooks, which are called with c.mu held.
+type stopHistory struct {
+	mu      sync.Mutex
+	samples map[string]stopSample
+}
+
+func (h *stopHistory) record(e Event) {
+	if e.Type != EventProcessStopped {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.samples == nil {
+		h.samples = make(map[string]stopSample)
+	}
+
+	ms := float64(e.Duration) / float64(time.Millisecond)
+	s := h.samples[e.Process]
+	if s.Samples == 0 {
+		s.AverageMS = ms
+	} else {
+		s.AverageMS += stopHistoryWeight * (ms - s.AverageMS)
+	}
+
+	s.Samples++
+	h.samples[e.Process] = s
+}
+
+func (h *stopHistory) average(name string) (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.samples[name]
+	return time.Duration(s.AverageMS * float64(time.Millisecond)), ok
+}
+
+func (h *stopHistory) load(path string) error {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var samples map[string]stopSample
+	if err := json.Unmarshal(b, &samples); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.samples = samples
+	return nil
+}
+
+func (h *stopHistory) save(path string) error {
+	h.mu.Lock()
+	b, err := json.MarshalIndent(h.samples, "", "  ")
+	h.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b, 0o644)
+}
+
+// WithStopHistoryFile persists the stop durations behind EstimateShutdown in
+// path, so estimates survive restarts of the binary. The file is read when
+// the conductor runs and written once shutdown completes.
+func WithStopHistoryFile(path string) Option {
+	return func(c *Conductor) {
+		c.onRun(func() (func(), error) {
+			if err := c.history.load(path); err != nil {
+				c.log.Warn("failed to load stop history", "path", path, "error", err)
+			}
+
+			return func() {
+				if err := c.history.save(path); err != nil {
+					c.log.Warn("failed to save stop history", "path", path, "error", err)
+				}
+			}, nil
+		})
+	}
+}
+
+// ShutdownEstimate is how long a graceful shutdown is expected to take,
+// based on how long each process took to stop before. Unknown lists the
+// processes that have never been stopped and so are not accounted for.
+type ShutdownEstimate struct {
+	Duration  time.Duration
+	Processes map[string]time.Duration
+	Unknown   []string
+}
+
+// EstimateShutdown estimates how long a graceful shutdown would take right
+// now. Each process is expected to take its rolling average stop duration,
+// capped by its StopTimeout, and dependency levels are stopped one after
+// another as in a real shutdown, within the SIGTERM shutdown policy.
+func (c *Conductor) EstimateShutdown() (ShutdownEstimate, error) {
+	c.mu.Lock()
+	if err := c.validate(); err != nil {
+		c.mu.Unlock()
+		return ShutdownEstimate{}, fmt.Errorf("estimate shutdown: %w", err)
+	}
+
+	levels := c.stopLevels()
+	policy := c.policy(sigTerminate)
+	c.mu.Unlock()
+
+	estimate := ShutdownEstimate{Processes: make(map[string]time.Duration)}
+	for _, level := range levels {
+		var longest, total time.Duration
+		for _, e := range level {
+			state, _ := e.status()
+			switch {
+			case state == ProcessStopped,
+				state == ProcessExited && !c.stopCompleted,
+				!e.started() && !c.stopUnstarted:
+				continue
+			}
+
+			d, ok := c.history.average(e.name())
+			if !ok {
+				estimate.Unknown = append(estimate.Unknown, e.name())
+				continue
+			}
+
+			if t, ok := as[StopTimeouter](e.process); ok {
+				d = min(d, t.StopTimeout())
+			}
+
+			estimate.Processes[e.name()] = d
+			longest = max(longest, d)
+			total += d
+		}
+
+		if policy.Concurrency > 0 && policy.Concurrency < len(level) {
+			longest = max(longest, total/time.Duration(policy.Concurrency))
+		}
+
+		estimate.Duration += longest
+	}
+
+	estimate.Duration = min(estimate.Duration, policy.Timeout)
+	return estimate, nil
+}
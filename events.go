@@ -0,0 +1,169 @@
+package parallel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventProcessStarted      EventType = "process_started"
+	EventProcessWarmedUp     EventType = "process_warmed_up"
+	EventProcessReady        EventType = "process_ready"
+	EventProcessExited       EventType = "process_exited"
+	EventProcessFailed       EventType = "process_failed"
+	EventProcessRestarting   EventType = "process_restarting"
+	EventProcessStopped      EventType = "process_stopped"
+	EventProcessStopFailed   EventType = "process_stop_failed"
+	EventProcessQuarantined  EventType = "process_quarantined"
+	EventProcessProgress     EventType = "process_progress"
+	EventPoolScaled          EventType = "pool_scaled"
+	EventCanaryStarted       EventType = "canary_started"
+	EventCanaryPromoted      EventType = "canary_promoted"
+	EventCanaryRolledBack    EventType = "canary_rolled_back"
+	EventStartupComplete     EventType = "startup_complete"
+	EventServiceRegistered   EventType = "service_registered"
+	EventShutdownStarted     EventType = "shutdown_started"
+	EventServiceDeregistered EventType = "service_deregistered"
+	EventShutdownComplete    EventType = "shutdown_complete"
+	EventShutdownSummary     EventType = "shutdown_summary"
+)
+
+type Event struct {
+	Type     EventType
+	Process  string
+	Time     time.Time
+	Duration time.Duration
+	Err      error
+
+	// Panic and Stack are set on EventProcessFailed when the process panicked.
+	Panic any
+	Stack []byte
+
+	// Repeated is set on EventProcessFailed to the number of failures of the
+	// process suppressed by WithErrorDedup since the previous event.
+	Repeated int
+
+	// Summary is set on EventShutdownSummary.
+	Summary *ShutdownSummary
+
+	// Progress is set on EventProcessProgress.
+	Progress *Progress
+
+	// Workers is set on EventPoolScaled to the new number of workers.
+	Workers int
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	record := struct {
+		Event      EventType        `json:"event"`
+		Process    string           `json:"process,omitempty"`
+		Timestamp  time.Time        `json:"timestamp"`
+		DurationMS float64          `json:"duration_ms,omitempty"`
+		Error      string           `json:"error,omitempty"`
+		Panic      string           `json:"panic,omitempty"`
+		Stack      string           `json:"stack,omitempty"`
+		Repeated   int              `json:"repeated,omitempty"`
+		Summary    *ShutdownSummary `json:"summary,omitempty"`
+		Progress   *Progress        `json:"progress,omitempty"`
+		Workers    int              `json:"workers,omitempty"`
+	}{
+		Event:      e.Type,
+		Process:    e.Process,
+		Timestamp:  e.Time,
+		DurationMS: float64(e.Duration) / float64(time.Millisecond),
+		Stack:      string(e.Stack),
+		Repeated:   e.Repeated,
+		Summary:    e.Summary,
+		Progress:   e.Progress,
+		Workers:    e.Workers,
+	}
+
+	if e.Err != nil {
+		record.Error = e.Err.Error()
+	}
+
+	if e.Panic != nil {
+		record.Panic = fmt.Sprint(e.Panic)
+	}
+
+	return json.Marshal(record)
+}
+
+// WithEventLog writes every lifecycle event to w as a JSON record, one per
+// line, separately from the human-readable log.
+func WithEventLog(w io.Writer) Option {
+	return func(c *Conductor) {
+		var mu sync.Mutex
+		enc := json.NewEncoder(w)
+		c.integrations = append(c.integrations, "event-log")
+
+		c.listeners = append(c.listeners, func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err := enc.Encode(e); err != nil {
+				c.log.Error("failed to write lifecycle event", "error", err)
+			}
+		})
+	}
+}
+
+// WithEventListener calls fn synchronously for every lifecycle event. fn
+// must not block.
+func WithEventListener(fn func(Event)) Option {
+	return func(c *Conductor) {
+		c.listeners = append(c.listeners, fn)
+	}
+}
+
+func (c *Conductor) emit(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	c.mu.Lock()
+	listeners := c.listeners
+	c.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(e)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for ch := range c.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel that receives every lifecycle event from now
+// on, until cancel is called. Events are dropped while the channel's buffer
+// is full, so a slow subscriber never holds up the conductor.
+func (c *Conductor) Subscribe(buffer int) (events <-chan Event, cancel func()) {
+	ch := make(chan Event, buffer)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.subscribers == nil {
+		c.subscribers = make(map[chan Event]struct{})
+	}
+
+	c.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		delete(c.subscribers, ch)
+	}
+}
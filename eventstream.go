@@ -0,0 +1,59 @@
+package parallel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// eventStreamBuffer is the number of events buffered for each client of
+// EventStreamHandler.
+const eventStreamBuffer = 64
+
+// EventStreamHandler streams lifecycle events to HTTP clients as
+// server-sent events, so dashboards can watch process state changes as they
+// happen instead of polling HealthHandler. Each event is sent with its type
+// as the SSE event name and its JSON record as the data. Repeated process
+// query parameters limit the stream to those processes; events that are not
+// about a process are always sent.
+func (c *Conductor) EventStreamHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		processes := r.URL.Query()["process"]
+		events, cancel := c.Subscribe(eventStreamBuffer)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case e := <-events:
+				if e.Process != "" && len(processes) > 0 && !slices.Contains(processes, e.Process) {
+					continue
+				}
+
+				data, err := json.Marshal(e)
+				if err != nil {
+					continue
+				}
+
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+					return
+				}
+
+				flusher.Flush()
+			}
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package parallel
+
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+// published is the most recently run conductor, whose state is exposed
+// through the "parallel" expvar map.
+var published atomic.Pointer[Conductor]
+
+func init() {
+	vars := expvar.NewMap("parallel")
+
+	vars.Set("state", expvar.Func(func() any {
+		c := published.Load()
+		if c == nil {
+			return stateIdle.String()
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return c.state.String()
+	}))
+
+	vars.Set("status", expvar.Func(func() any {
+		c := published.Load()
+		if c == nil {
+			return StatusIdle
+		}
+
+		return c.Status()
+	}))
+
+	vars.Set("shutdown_reason", expvar.Func(func() any {
+		c := published.Load()
+		if c == nil {
+			return ""
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return c.reason
+	}))
+
+	vars.Set("processes", expvar.Func(func() any {
+		type process struct {
+			State       ProcessState `json:"state"`
+			Restarts    int          `json:"restarts"`
+			Quarantined bool         `json:"quarantined,omitempty"`
+		}
+
+		processes := make(map[string]process)
+
+		c := published.Load()
+		if c == nil {
+			return processes
+		}
+
+		c.mu.Lock()
+		entries := c.entries
+		c.mu.Unlock()
+
+		for _, e := range entries {
+			state, restarts := e.status()
+			processes[e.name()] = process{
+				State:       state,
+				Restarts:    restarts,
+				Quarantined: e.isQuarantined(),
+			}
+		}
+
+		return processes
+	}))
+}
@@ -0,0 +1,66 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// logFlushTimeout bounds the flushes run once shutdown completes,
+// separately from the shutdown budget.
+const logFlushTimeout = 2 * time.Second
+
+// WithFlush calls each of fns once every process has stopped and the
+// shutdown has been logged, right before ThenStop returns, so buffered log
+// and telemetry sinks such as a zap logger, a bufio.Writer or an OTel
+// exporter do not lose the shutdown logs. The flushes run concurrently with
+// a shared timeout of 2s; ThenStop waits no longer for them, and failures
+// are logged.
+func WithFlush(fns ...func(ctx context.Context) error) Option {
+	return func(c *Conductor) {
+		c.flushes = append(c.flushes, fns...)
+	}
+}
+
+// flush runs the WithFlush functions.
+func (c *Conductor) flush() {
+	c.mu.Lock()
+	fns := c.flushes
+	c.mu.Unlock()
+
+	c.runFlushes(fns)
+}
+
+func (c *Conductor) runFlushes(fns []func(ctx context.Context) error) {
+	if len(fns) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), logFlushTimeout)
+	defer cancel()
+
+	errs := make(chan error, len(fns))
+	for _, fn := range fns {
+		go func() {
+			errs <- fn(ctx)
+		}()
+	}
+
+	var failed []error
+	for range fns {
+		select {
+		case err := <-errs:
+			if err != nil {
+				failed = append(failed, err)
+			}
+		case <-ctx.Done():
+			failed = append(failed, ctx.Err())
+			c.log.Warn("failed to flush", "error", errors.Join(failed...))
+			return
+		}
+	}
+
+	if len(failed) > 0 {
+		c.log.Warn("failed to flush", "error", errors.Join(failed...))
+	}
+}
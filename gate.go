@@ -0,0 +1,20 @@
+package parallel
+
+import "net/http"
+
+// Gate wraps next so that requests are answered with 503 Service
+// Unavailable unless the conductor's status is healthy or degraded, which
+// keeps traffic away while processes are starting or shutting down.
+func (c *Conductor) Gate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := c.Status()
+		if !status.Serving() {
+			w.Header().Set("X-Parallel-Status", string(status))
+			w.Header().Set("Connection", "close")
+			http.Error(w, "service is "+string(status), http.StatusServiceUnavailable)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,108 @@
+package parallel
+
+import (
+	"context"
+	"maps"
+)
+
+// Labeled is implemented by processes that carry descriptive labels. The
+// labels are attached to the process's pprof labels and exposed on its
+// Handle.
+type Labeled interface {
+	Labels() map[string]string
+}
+
+// Handle is a read-only view of a registered process with methods to
+// control it individually.
+type Handle struct {
+	conductor *Conductor
+	entry     *entry
+}
+
+func (h *Handle) Name() string {
+	return h.entry.name()
+}
+
+func (h *Handle) Process() Process {
+	return h.entry.process
+}
+
+func (h *Handle) State() ProcessState {
+	state, _ := h.entry.status()
+	return state
+}
+
+func (h *Handle) Restarts() int {
+	_, restarts := h.entry.status()
+	return restarts
+}
+
+// Labels returns the process's labels, always including "process" set to
+// its name.
+func (h *Handle) Labels() map[string]string {
+	return labels(h.entry.process)
+}
+
+// Restart stops the process and runs it again. Its interruption is not
+// treated as a failure.
+func (h *Handle) Restart(ctx context.Context) error {
+	err := h.conductor.restart(ctx, h.entry)
+	h.conductor.audit(ctx, "restart", h.Name(), err)
+	return err
+}
+
+// Stop stops only this process; the rest of the conductor keeps running.
+func (h *Handle) Stop(ctx context.Context) error {
+	err := h.conductor.halt(ctx, h.entry)
+	h.conductor.audit(ctx, "stop", h.Name(), err)
+	return err
+}
+
+// Processes returns handles for every registered process in registration
+// order.
+func (c *Conductor) Processes() []*Handle {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	handles := make([]*Handle, len(c.entries))
+	for i, e := range c.entries {
+		handles[i] = &Handle{conductor: c, entry: e}
+	}
+
+	return handles
+}
+
+func (c *Conductor) Names() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	names := make([]string, len(c.entries))
+	for i, e := range c.entries {
+		names[i] = e.name()
+	}
+
+	return names
+}
+
+func (c *Conductor) Lookup(name string) (*Handle, bool) {
+	e := c.lookup(name)
+	if e == nil {
+		return nil, false
+	}
+
+	return &Handle{conductor: c, entry: e}, true
+}
+
+func labels(p Process) map[string]string {
+	l := make(map[string]string)
+	if labeled, ok := as[Labeled](p); ok {
+		maps.Copy(l, labeled.Labels())
+	}
+
+	if group := groupOf(p); group != "" {
+		l["group"] = group
+	}
+
+	l["process"] = p.Name()
+	return l
+}
@@ -0,0 +1,39 @@
+package parallel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInflightReuseAfterAbandonedWait(t *testing.T) {
+	var f inflight
+	if !isClosed(f.idle()) {
+		t.Fatal("idle not closed with nothing in flight")
+	}
+
+	f.add()
+	abandoned := f.idle()
+	select {
+	case <-abandoned:
+		t.Fatal("idle closed while in flight")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Counting up again while the abandoned wait is pending is allowed.
+	f.add()
+	f.done()
+	if isClosed(abandoned) {
+		t.Fatal("idle closed while still in flight")
+	}
+
+	f.done()
+	if !isClosed(abandoned) || !isClosed(f.idle()) {
+		t.Fatal("idle not closed once nothing is in flight")
+	}
+
+	f.add()
+	if isClosed(f.idle()) {
+		t.Fatal("idle of a new round closed while in flight")
+	}
+	f.done()
+}
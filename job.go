@@ -0,0 +1,250 @@
+package parallel
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrUnknownJob  = errors.New("unknown job")
+	ErrJobFinished = errors.New("job has already finished")
+)
+
+// keepFinished is how many finished jobs a pool keeps the status of.
+const keepFinished = 256
+
+// JobID identifies a job submitted to a Pool.
+type JobID uint64
+
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+	JobCancelled JobState = "cancelled"
+)
+
+// JobStatus is the state of a job in a Pool. Started and Finished are zero
+// until the job starts and finishes, Attempts counts the runs of a finished
+// job, Err is set when it failed and Progress is what the job last reported
+// with ReportProgress.
+type JobStatus struct {
+	ID        JobID
+	State     JobState
+	Priority  string
+	Submitted time.Time
+	Started   time.Time
+	Finished  time.Time
+	Attempts  int
+	Err       string
+	Progress  Progress
+}
+
+// MarshalJSON leaves out the times that are not set yet.
+func (s JobStatus) MarshalJSON() ([]byte, error) {
+	v := struct {
+		ID        JobID      `json:"id"`
+		State     JobState   `json:"state"`
+		Priority  string     `json:"priority"`
+		Submitted time.Time  `json:"submitted"`
+		Started   *time.Time `json:"started,omitempty"`
+		Finished  *time.Time `json:"finished,omitempty"`
+		Attempts  int        `json:"attempts,omitempty"`
+		Err       string     `json:"error,omitempty"`
+		Progress  *Progress  `json:"progress,omitempty"`
+	}{ID: s.ID, State: s.State, Priority: s.Priority, Submitted: s.Submitted, Attempts: s.Attempts, Err: s.Err}
+
+	if s.Progress != (Progress{}) {
+		v.Progress = &s.Progress
+	}
+
+	if !s.Started.IsZero() {
+		v.Started = &s.Started
+	}
+
+	if !s.Finished.IsZero() {
+		v.Finished = &s.Finished
+	}
+
+	return json.Marshal(v)
+}
+
+// jobRecord tracks a submitted job. Its fields are guarded by the jobs
+// mutex of its pool.
+type jobRecord struct {
+	status   JobStatus
+	cancel   context.CancelFunc
+	progress progressTracker
+}
+
+// track records a job about to be queued and returns its ID.
+func (p *Pool) track(q *queuedJob) JobID {
+	p.jobs.Lock()
+	defer p.jobs.Unlock()
+
+	if p.records == nil {
+		p.records = make(map[JobID]*jobRecord)
+	}
+
+	p.lastID++
+	q.record = &jobRecord{status: JobStatus{
+		ID:        p.lastID,
+		State:     JobQueued,
+		Priority:  q.priority.String(),
+		Submitted: q.submitted,
+	}}
+
+	p.records[p.lastID] = q.record
+	return p.lastID
+}
+
+// untrack forgets a job that could not be queued.
+func (p *Pool) untrack(id JobID) {
+	p.jobs.Lock()
+	defer p.jobs.Unlock()
+
+	delete(p.records, id)
+}
+
+// begin marks the job of q as running with its own cancellable context
+// derived from ctx, or reports false when it was cancelled while queued.
+func (p *Pool) begin(ctx context.Context, q queuedJob) (context.Context, bool) {
+	p.jobs.Lock()
+	defer p.jobs.Unlock()
+
+	if q.record.status.State == JobCancelled {
+		return nil, false
+	}
+
+	ctx, cancel := context.WithCancel(withProgress(ctx, &q.record.progress))
+	q.record.status.State = JobRunning
+	q.record.status.Started = time.Now()
+	q.record.cancel = cancel
+	return ctx, true
+}
+
+// end records how the job of q finished after attempts, reporting whether
+// it failed rather than being cancelled. A job failing once ctx, the
+// context of the workers, is done was cancelled by the pool stopping.
+func (p *Pool) end(ctx context.Context, q queuedJob, err error, attempts int) bool {
+	p.jobs.Lock()
+	defer p.jobs.Unlock()
+
+	r := q.record
+	r.cancel()
+	r.status.Attempts = attempts
+
+	failed := false
+	switch {
+	case r.status.State == JobCancelled:
+	case err != nil && ctx.Err() != nil:
+		r.status.State = JobCancelled
+	case err != nil:
+		r.status.State = JobFailed
+		r.status.Err = err.Error()
+		failed = true
+	default:
+		r.status.State = JobSucceeded
+	}
+
+	r.status.Finished = time.Now()
+	p.retire(r.status.ID)
+	return failed
+}
+
+// retire remembers the finished job id, forgetting the oldest finished job
+// beyond keepFinished. The caller must hold p.jobs.
+func (p *Pool) retire(id JobID) {
+	p.finished = append(p.finished, id)
+	if len(p.finished) > keepFinished {
+		delete(p.records, p.finished[0])
+		p.finished = p.finished[1:]
+	}
+}
+
+// snapshot returns the status of r with its current progress. The caller
+// must hold the jobs mutex of its pool.
+func (r *jobRecord) snapshot() JobStatus {
+	s := r.status
+	s.Progress.Done, s.Progress.Total = r.progress.Progress()
+	return s
+}
+
+// Status returns the status of the job id. The status of finished jobs is
+// kept for the 256 most recent ones.
+func (p *Pool) Status(id JobID) (JobStatus, error) {
+	p.jobs.Lock()
+	defer p.jobs.Unlock()
+
+	r, ok := p.records[id]
+	if !ok {
+		return JobStatus{}, fmt.Errorf("%w: %d", ErrUnknownJob, id)
+	}
+
+	return r.snapshot(), nil
+}
+
+// Jobs returns the status of the queued, running and recently finished
+// jobs, ordered by ID.
+func (p *Pool) Jobs() []JobStatus {
+	p.jobs.Lock()
+	defer p.jobs.Unlock()
+
+	jobs := make([]JobStatus, 0, len(p.records))
+	for _, r := range p.records {
+		jobs = append(jobs, r.snapshot())
+	}
+
+	slices.SortFunc(jobs, func(a, b JobStatus) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return jobs
+}
+
+// pool returns the Pool registered as the process called name.
+func (c *Conductor) pool(name string) (*Pool, error) {
+	e := c.lookup(name)
+	if e == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProcess, name)
+	}
+
+	p, ok := as[*Pool](e.process)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not a pool", ErrUnknownProcess, name)
+	}
+
+	return p, nil
+}
+
+// Cancel cancels the job id. A queued job is dropped before it starts, and
+// the context of a running job is cancelled.
+func (p *Pool) Cancel(id JobID) error {
+	p.jobs.Lock()
+	defer p.jobs.Unlock()
+
+	r, ok := p.records[id]
+	switch {
+	case !ok:
+		return fmt.Errorf("%w: %d", ErrUnknownJob, id)
+	case !r.status.Finished.IsZero():
+		return fmt.Errorf("%w: %d", ErrJobFinished, id)
+	}
+
+	if r.status.State == JobQueued {
+		r.status.Finished = time.Now()
+		p.retire(id)
+	} else {
+		r.cancel()
+	}
+
+	r.status.State = JobCancelled
+	return nil
+}
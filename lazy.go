@@ -0,0 +1,136 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// OnDemand is implemented by lazy processes, which are not started by Run
+// but on first use, by Conductor.Ensure or when a process depending on them
+// starts. A positive IdleTimeout stops the process again once it has not
+// been ensured for that long and no running process depends on it.
+type OnDemand interface {
+	IdleTimeout() time.Duration
+}
+
+type lazyProcess struct {
+	Process
+	idle time.Duration
+}
+
+// Lazy makes p a lazy process, for expensive optional subsystems such as
+// debug profilers or admin UIs. An idle timeout of zero keeps it running
+// once started.
+func Lazy(p Process, idle time.Duration) Process {
+	return &lazyProcess{
+		Process: p,
+		idle:    idle,
+	}
+}
+
+func (l *lazyProcess) IdleTimeout() time.Duration {
+	return l.idle
+}
+
+func (l *lazyProcess) Unwrap() Process {
+	return l.Process
+}
+
+func isLazy(p Process) bool {
+	_, ok := as[OnDemand](p)
+	return ok
+}
+
+// Ensure starts the lazy process called name if it is not running and
+// waits until it is ready, restarting its idle timer. For other processes
+// it only waits until they are ready.
+func (c *Conductor) Ensure(ctx context.Context, name string) error {
+	c.mu.Lock()
+	e := c.find(name)
+	switch {
+	case c.state != stateRunning:
+		c.mu.Unlock()
+		return ErrNotRunning
+	case e == nil:
+		c.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUnknownProcess, name)
+	case c.skip(e):
+		c.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrProcessSkipped, name)
+	}
+
+	c.wake(e)
+	ready := e.readyChan()
+	c.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wake starts e if it is a lazy process that is not already waiting,
+// starting or running, and restarts
+// its idle timer. The caller must hold c.mu.
+func (c *Conductor) wake(e *entry) {
+	l, ok := as[OnDemand](e.process)
+	if !ok || c.skip(e) {
+		return
+	}
+
+	if e.claimStart() {
+		c.log.Info("starting lazy process", "process", e.name())
+		e.resetReady()
+		c.begin(c.ctx, e)
+	}
+
+	d := l.IdleTimeout()
+	if d <= 0 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.idle == nil {
+		e.idle = time.AfterFunc(d, func() { c.stopIdle(e, d) })
+	} else {
+		e.idle.Reset(d)
+	}
+}
+
+// stopIdle stops the lazy process e after it has been idle for d, unless a
+// running process depends on it, in which case it checks again after d.
+func (c *Conductor) stopIdle(e *entry, d time.Duration) {
+	c.mu.Lock()
+	if c.state != stateRunning || !e.running() {
+		c.mu.Unlock()
+		return
+	}
+
+	for _, other := range c.entries {
+		for _, dep := range dependsOn(other.process) {
+			if dep == e.name() && other.running() {
+				e.mu.Lock()
+				e.idle.Reset(d)
+				e.mu.Unlock()
+
+				c.mu.Unlock()
+				return
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	c.log.Info("stopping idle process", "process", e.name(), "idle", d)
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := c.stopProcess(ctx, e); err == nil {
+		e.transition(ProcessIdle, ProcessStopped)
+	}
+}
@@ -0,0 +1,79 @@
+package parallel_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/franklad/parallel"
+	"github.com/franklad/parallel/conductortest"
+)
+
+type slowReady struct {
+	parallel.Process
+	delay time.Duration
+}
+
+func (s slowReady) Ready(ctx context.Context) error {
+	select {
+	case <-time.After(s.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s slowReady) Unwrap() parallel.Process {
+	return s.Process
+}
+
+func blocking(name string, runs *atomic.Int32) parallel.Process {
+	return parallel.Task(name, func(ctx context.Context) error {
+		if runs != nil {
+			runs.Add(1)
+		}
+
+		<-ctx.Done()
+		return nil
+	})
+}
+
+func TestEnsureDuringDependencyWait(t *testing.T) {
+	var runs atomic.Int32
+	dep := slowReady{Process: blocking("dep", nil), delay: 200 * time.Millisecond}
+	lazy := parallel.Lazy(parallel.After(blocking("lazy", &runs), "dep"), 0)
+
+	c := conductortest.New(dep, lazy).With(parallel.WithLogger(discard()))
+	c.Run(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = c.Ensure(ctx, "lazy")
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Ensure %d: %v", i, err)
+		}
+	}
+
+	c.Shutdown("test")
+	if err := c.ThenStop(); err != nil {
+		t.Fatalf("ThenStop: %v", err)
+	}
+
+	if n := runs.Load(); n != 1 {
+		t.Errorf("lazy process ran %d times, want 1", n)
+	}
+}
@@ -0,0 +1,106 @@
+package parallel
+
+import (
+	"bytes"
+	"regexp"
+	"runtime/pprof"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const leakPollInterval = 10 * time.Millisecond
+
+var processLabel = regexp.MustCompile(`"process":("(?:[^"\\]|\\.)*")`)
+
+// Leak is a group of goroutines with the same stack that a process started
+// and that were still running after shutdown.
+type Leak struct {
+	Process    string
+	Goroutines int
+	Stack      string
+}
+
+// WithLeakDetection checks after every shutdown that no goroutine labeled
+// with one of the conductor's processes is left, waiting up to grace for
+// them to exit. Processes run with their labels as pprof labels, which the
+// goroutines they start inherit, so a leaked goroutine can be traced back
+// to the process whose Stop did not stop it. Leaks are logged with their
+// stacks and returned by Leaks.
+func WithLeakDetection(grace time.Duration) Option {
+	return func(c *Conductor) {
+		c.leakGrace = grace
+		c.detectLeaks = true
+	}
+}
+
+// Leaks returns the goroutine leaks found after the last shutdown when
+// leak detection is enabled.
+func (c *Conductor) Leaks() []Leak {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.leaks
+}
+
+// findLeaks waits up to grace for the goroutines of the named processes to
+// exit and logs the ones that do not.
+func (c *Conductor) findLeaks(names []string, grace time.Duration) []Leak {
+	deadline := time.Now().Add(grace)
+
+	var leaks []Leak
+	for {
+		leaks = goroutineLeaks(names)
+		if len(leaks) == 0 || time.Now().After(deadline) {
+			break
+		}
+
+		time.Sleep(leakPollInterval)
+	}
+
+	for _, l := range leaks {
+		c.log.Warn("goroutine leaked by process", "process", l.Process, "goroutines", l.Goroutines, "stack", l.Stack)
+	}
+
+	return leaks
+}
+
+// goroutineLeaks returns the goroutines labeled with one of the named
+// processes, parsed from the goroutine profile.
+func goroutineLeaks(names []string) []Leak {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return nil
+	}
+
+	var leaks []Leak
+	for _, block := range strings.Split(buf.String(), "\n\n") {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		if len(lines) < 2 || !strings.HasPrefix(lines[1], "# labels: ") {
+			continue
+		}
+
+		m := processLabel.FindStringSubmatch(lines[1])
+		if m == nil {
+			continue
+		}
+
+		name, err := strconv.Unquote(m[1])
+		if err != nil || !slices.Contains(names, name) {
+			continue
+		}
+
+		count, _, _ := strings.Cut(lines[0], " @")
+		n, _ := strconv.Atoi(count)
+
+		var stack []string
+		for _, frame := range lines[2:] {
+			stack = append(stack, strings.TrimPrefix(frame, "#\t"))
+		}
+
+		leaks = append(leaks, Leak{Process: name, Goroutines: n, Stack: strings.Join(stack, "\n")})
+	}
+
+	return leaks
+}
@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package parallel
+
+import (
+	"errors"
+	"os"
+)
+
+func lockFile(*os.File) error {
+	return errors.New("file locking is not supported on this platform")
+}
+
+func unlockFile(*os.File) error {
+	return nil
+}
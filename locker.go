@@ -0,0 +1,117 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrLockLost = errors.New("lock lost")
+
+// Locker is a distributed lock, for example backed by Redis, etcd or a
+// postgres advisory lock. Lock blocks until the lock is held or ctx is
+// done, and returns a channel that is closed if the lock is lost while
+// held; a nil channel means it is never lost. Unlock releases a held lock.
+type Locker interface {
+	Lock(ctx context.Context) (lost <-chan struct{}, err error)
+	Unlock(ctx context.Context) error
+}
+
+type lockedProcess struct {
+	Process
+	locker Locker
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	holding bool
+}
+
+// WithLock makes p run only while locker is held, a lighter-weight
+// alternative to leader election for singleton jobs. Run waits for the
+// lock, runs p while it is held and releases it once p returns. If the lock
+// is lost, p is stopped and the process fails with ErrLockLost. A replica
+// waiting for the lock counts as ready, so p's readiness and warmup are not
+// waited for.
+func WithLock(p Process, locker Locker) Process {
+	return &lockedProcess{
+		Process: p,
+		locker:  locker,
+	}
+}
+
+func (l *lockedProcess) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	l.mu.Lock()
+	l.cancel = cancel
+	l.mu.Unlock()
+
+	lost, err := l.locker.Lock(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		return err
+	}
+
+	defer l.locker.Unlock(context.WithoutCancel(ctx))
+
+	l.mu.Lock()
+	l.holding = true
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.holding = false
+		l.mu.Unlock()
+	}()
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	result := make(chan error, 1)
+	go func() { result <- l.Process.Run(ctx) }()
+
+	select {
+	case err := <-result:
+		return err
+	case <-lost:
+		cancel()
+		l.Process.Stop(context.WithoutCancel(ctx))
+		<-result
+		return ErrLockLost
+	}
+}
+
+// Stop stops p if the lock is held, and otherwise gives up waiting for it.
+func (l *lockedProcess) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	cancel, holding := l.cancel, l.holding
+	l.mu.Unlock()
+
+	var err error
+	if holding {
+		err = l.Process.Stop(ctx)
+	}
+
+	if cancel != nil {
+		cancel()
+	}
+
+	return err
+}
+
+func (l *lockedProcess) Ready(ctx context.Context) error {
+	return nil
+}
+
+func (l *lockedProcess) Warmup(ctx context.Context) error {
+	return nil
+}
+
+func (l *lockedProcess) Unwrap() Process {
+	return l.Process
+}
@@ -0,0 +1,191 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	colorRed    = 31
+	colorGreen  = 32
+	colorYellow = 33
+	colorBlue   = 34
+	colorCyan   = 36
+	colorDim    = 90
+)
+
+// WithLogger sends the conductor's log to l instead of the default JSON log
+// on stdout. Integrations for third-party loggers provide a slog.Handler.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Conductor) {
+		c.log = l.With("engine", "conductor")
+	}
+}
+
+// WithDevLogging replaces the JSON log with colored console output showing
+// the time since start and the process name aligned in its own column. Like
+// the JSON log, it leaves out records below slog.LevelInfo.
+func WithDevLogging() Option {
+	return WithDevLoggingLevel(nil)
+}
+
+// WithDevLoggingLevel is WithDevLogging showing records at level and above.
+// As with slog.HandlerOptions.Level, a nil level means slog.LevelInfo, and a
+// *slog.LevelVar changes the level while the conductor runs.
+func WithDevLoggingLevel(level slog.Leveler) Option {
+	return func(c *Conductor) {
+		h := &devHandler{
+			shared: &devShared{
+				out:   os.Stdout,
+				began: time.Now(),
+				level: level,
+			},
+		}
+
+		c.log = slog.New(h).With("engine", "conductor")
+	}
+}
+
+type devShared struct {
+	out   io.Writer
+	began time.Time
+	level slog.Leveler
+
+	mu    sync.Mutex
+	width int
+}
+
+type devHandler struct {
+	shared  *devShared
+	process string
+	attrs   string
+	prefix  string
+}
+
+func (h *devHandler) Enabled(_ context.Context, l slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.shared.level != nil {
+		minLevel = h.shared.level.Level()
+	}
+
+	return l >= minLevel
+}
+
+func (h *devHandler) Handle(_ context.Context, r slog.Record) error {
+	process := h.process
+
+	var attrs strings.Builder
+	attrs.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "process" && h.prefix == "" {
+			process = a.Value.String()
+			return true
+		}
+
+		writeAttr(&attrs, h.prefix, a)
+		return true
+	})
+
+	if process == "" {
+		process = "conductor"
+	}
+
+	s := h.shared
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.width = max(s.width, len(process))
+
+	elapsed := fmt.Sprintf("+%8.3fs", r.Time.Sub(s.began).Seconds())
+	_, err := fmt.Fprintf(s.out, "%s %s %s %s%s\n",
+		colorize(elapsed, colorDim),
+		level(r.Level),
+		colorize(fmt.Sprintf("%-*s", s.width, process), colorCyan),
+		"\x1b[1m"+r.Message+"\x1b[0m",
+		attrs.String(),
+	)
+
+	return err
+}
+
+func (h *devHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	for _, a := range attrs {
+		switch {
+		case a.Key == "engine" && h.prefix == "":
+		case a.Key == "process" && h.prefix == "":
+			next.process = a.Value.String()
+		default:
+			writeAttr(&b, h.prefix, a)
+		}
+	}
+
+	next.attrs = b.String()
+	return &next
+}
+
+func (h *devHandler) WithGroup(name string) slog.Handler {
+	next := *h
+	next.prefix = h.prefix + name + "."
+	return &next
+}
+
+func writeAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+
+		for _, ga := range a.Value.Group() {
+			writeAttr(b, prefix, ga)
+		}
+
+		return
+	}
+
+	if a.Key == "" {
+		return
+	}
+
+	value := a.Value.String()
+	if strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(colorize(prefix+a.Key+"=", colorCyan))
+	if a.Key == "error" {
+		value = colorize(value, colorRed)
+	}
+
+	b.WriteString(value)
+}
+
+func level(l slog.Level) string {
+	switch {
+	case l >= slog.LevelError:
+		return colorize("ERR", colorRed)
+	case l >= slog.LevelWarn:
+		return colorize("WRN", colorYellow)
+	case l >= slog.LevelInfo:
+		return colorize("INF", colorGreen)
+	default:
+		return colorize("DBG", colorBlue)
+	}
+}
+
+func colorize(s string, color int) string {
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", color, s)
+}
@@ -0,0 +1,66 @@
+package parallel
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newDevLogger(level slog.Leveler) (*slog.Logger, *bytes.Buffer) {
+	var out bytes.Buffer
+	h := &devHandler{shared: &devShared{out: &out, began: time.Now(), level: level}}
+	return slog.New(h).With("process", "api"), &out
+}
+
+func TestDevLoggingLevel(t *testing.T) {
+	var debug slog.LevelVar
+	debug.Set(slog.LevelDebug)
+
+	tests := []struct {
+		name  string
+		level slog.Leveler
+		debug bool
+		info  bool
+	}{
+		{"default", nil, false, true},
+		{"debug", &debug, true, true},
+		{"warn", slog.LevelWarn, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, out := newDevLogger(tt.level)
+			log.Debug("debug record")
+			log.Info("info record")
+			log.Warn("warn record")
+
+			got := out.String()
+			if strings.Contains(got, "debug record") != tt.debug {
+				t.Errorf("debug record shown = %t, want %t", !tt.debug, tt.debug)
+			}
+
+			if strings.Contains(got, "info record") != tt.info {
+				t.Errorf("info record shown = %t, want %t", !tt.info, tt.info)
+			}
+
+			if !strings.Contains(got, "warn record") {
+				t.Error("warn record not shown")
+			}
+		})
+	}
+}
+
+func TestDevLoggingLevelVar(t *testing.T) {
+	var level slog.LevelVar
+	log, out := newDevLogger(&level)
+
+	log.Debug("before")
+	level.Set(slog.LevelDebug)
+	log.Debug("after")
+
+	if got := out.String(); strings.Contains(got, "before") || !strings.Contains(got, "after") {
+		t.Errorf("output %q does not follow the level change", got)
+	}
+}
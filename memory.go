@@ -0,0 +1,113 @@
+package parallel
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+type MemoryAction int
+
+const (
+	MemoryActionLog MemoryAction = iota
+	MemoryActionRestart
+	MemoryActionShutdown
+)
+
+func (a MemoryAction) String() string {
+	switch a {
+	case MemoryActionLog:
+		return "log"
+	case MemoryActionRestart:
+		return "restart"
+	case MemoryActionShutdown:
+		return "shutdown"
+	default:
+		return "unknown"
+	}
+}
+
+// MemoryReporter is implemented by processes that can report their own
+// memory usage, such as adapters supervising an external command.
+type MemoryReporter interface {
+	MemoryUsage() (uint64, error)
+}
+
+// MemoryBudget caps the memory usage of the named process, which must
+// implement MemoryReporter. Leave Process empty to budget the whole binary
+// using runtime statistics; restarting is not possible there, so
+// MemoryActionRestart falls back to a graceful shutdown.
+type MemoryBudget struct {
+	Process string
+	Limit   uint64
+	Action  MemoryAction
+}
+
+// WithMemoryWatchdog registers a process that checks every budget each
+// interval and takes the budget's action once its limit is exceeded.
+func WithMemoryWatchdog(interval time.Duration, budgets ...MemoryBudget) Option {
+	return func(c *Conductor) {
+		w := &memoryWatchdog{
+			conductor: c,
+			budgets:   budgets,
+		}
+
+		c.register(&periodic{
+			name:     "memory-watchdog",
+			interval: interval,
+			tick:     w.check,
+		})
+	}
+}
+
+type memoryWatchdog struct {
+	conductor *Conductor
+	budgets   []MemoryBudget
+}
+
+func (w *memoryWatchdog) check(ctx context.Context) {
+	for _, b := range w.budgets {
+		var (
+			usage uint64
+			e     *entry
+		)
+
+		if b.Process == "" {
+			var m runtime.MemStats
+			runtime.ReadMemStats(&m)
+			usage = m.Sys - m.HeapReleased
+		} else {
+			if e = w.conductor.lookup(b.Process); e == nil {
+				continue
+			}
+
+			r, ok := as[MemoryReporter](e.process)
+			if !ok {
+				continue
+			}
+
+			var err error
+			if usage, err = r.MemoryUsage(); err != nil {
+				w.conductor.log.Debug("failed to read memory usage", "process", b.Process, "error", err)
+				continue
+			}
+		}
+
+		if usage <= b.Limit {
+			continue
+		}
+
+		w.conductor.log.Warn("memory budget exceeded", "process", b.Process, "usage", usage, "limit", b.Limit, "action", b.Action.String())
+
+		switch {
+		case b.Action == MemoryActionRestart && e != nil:
+			go func() {
+				if err := w.conductor.restart(ctx, e); err != nil {
+					w.conductor.log.Error("failed to restart process", "process", e.name(), "error", err)
+				}
+			}()
+		case b.Action != MemoryActionLog:
+			w.conductor.shutdown("memory budget exceeded", nil)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package parallel
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+type Tag struct {
+	Key   string
+	Value string
+}
+
+type MetricsSink interface {
+	Gauge(name string, value float64, tags ...Tag)
+	Count(name string, delta int64, tags ...Tag)
+	Timing(name string, d time.Duration, tags ...Tag)
+}
+
+type nopSink struct{}
+
+func (nopSink) Gauge(string, float64, ...Tag)        {}
+func (nopSink) Count(string, int64, ...Tag)          {}
+func (nopSink) Timing(string, time.Duration, ...Tag) {}
+
+func WithMetrics(sink MetricsSink) Option {
+	return func(c *Conductor) {
+		c.metrics = sink
+	}
+}
+
+// WithRuntimeMetrics registers a process that samples goroutine count, heap
+// usage, and GC pauses every interval and publishes them to the conductor's
+// metrics sink.
+func WithRuntimeMetrics(interval time.Duration) Option {
+	return func(c *Conductor) {
+		r := &runtimeMetrics{conductor: c}
+
+		c.register(&periodic{
+			name:     "runtime-metrics",
+			interval: interval,
+			tick:     r.sample,
+		})
+	}
+}
+
+type runtimeMetrics struct {
+	conductor *Conductor
+	numGC     uint32
+}
+
+func (r *runtimeMetrics) sample(context.Context) {
+	sink := r.conductor.sink()
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	sink.Gauge("conductor.uptime_seconds", r.conductor.uptime().Seconds())
+	sink.Gauge("runtime.goroutines", float64(runtime.NumGoroutine()))
+	sink.Gauge("runtime.heap_alloc_bytes", float64(m.HeapAlloc))
+	sink.Gauge("runtime.heap_inuse_bytes", float64(m.HeapInuse))
+	sink.Gauge("runtime.heap_objects", float64(m.HeapObjects))
+	sink.Gauge("runtime.sys_bytes", float64(m.Sys))
+
+	// PauseNs is a circular buffer of the most recent 256 pauses, so only
+	// the pauses since the previous sample that are still in it are sent.
+	pauses := m.NumGC - r.numGC
+	if pauses > uint32(len(m.PauseNs)) {
+		pauses = uint32(len(m.PauseNs))
+	}
+
+	for i := uint32(0); i < pauses; i++ {
+		idx := (m.NumGC - i + uint32(len(m.PauseNs)) - 1) % uint32(len(m.PauseNs))
+		sink.Timing("runtime.gc_pause", time.Duration(m.PauseNs[idx]))
+	}
+
+	sink.Count("runtime.gc_cycles", int64(m.NumGC-r.numGC))
+	r.numGC = m.NumGC
+}
+
+// recordEvent translates lifecycle events into conductor metrics.
+func (c *Conductor) recordEvent(e Event) {
+	sink := c.sink()
+	tags := []Tag{{Key: "process", Value: e.Process}}
+
+	switch e.Type {
+	case EventProcessStarted:
+		sink.Count("process.starts", 1, tags...)
+	case EventProcessWarmedUp:
+		sink.Timing("process.warmup_duration", e.Duration, tags...)
+	case EventProcessReady:
+		sink.Timing("process.time_to_ready", e.Duration, tags...)
+	case EventProcessFailed:
+		sink.Count("process.failures", 1, tags...)
+	case EventProcessRestarting:
+		sink.Count("process.restarts", 1, tags...)
+	case EventProcessStopped, EventProcessStopFailed:
+		sink.Timing("process.stop_duration", e.Duration, tags...)
+	case EventStartupComplete:
+		sink.Timing("conductor.startup_duration", e.Duration)
+	case EventShutdownComplete:
+		sink.Timing("conductor.shutdown_duration", e.Duration)
+		sink.Gauge("conductor.uptime_seconds", c.uptime().Seconds())
+	}
+
+	if e.Process != "" {
+		var degraded float64
+		if c.Status() == StatusDegraded {
+			degraded = 1
+		}
+
+		sink.Gauge("conductor.degraded", degraded)
+	}
+}
+
+func (c *Conductor) uptime() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.startup == nil {
+		return 0
+	}
+
+	return time.Since(c.startup.began)
+}
+
+func (c *Conductor) sink() MetricsSink {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.metrics
+}
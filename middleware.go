@@ -0,0 +1,54 @@
+package parallel
+
+type Middleware func(Process) Process
+
+func chain(p Process, mw []Middleware) Process {
+	for i := len(mw) - 1; i >= 0; i-- {
+		p = mw[i](p)
+	}
+
+	return p
+}
+
+// Use wraps every registered process with the given middleware. The first
+// middleware becomes the outermost wrapper, and later calls to Use wrap
+// around earlier ones. Processes added afterwards are wrapped the same way.
+func (c *Conductor) Use(mw ...Middleware) *Conductor {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, e := range c.entries {
+		e.process = chain(e.process, mw)
+	}
+
+	c.middleware = append(c.middleware, mw)
+	return c
+}
+
+func (c *Conductor) wrap(p Process) Process {
+	for _, mw := range c.middleware {
+		p = chain(p, mw)
+	}
+
+	return p
+}
+
+// as returns the first process in the Unwrap chain of p that implements T,
+// so that wrappers do not hide the optional interfaces of what they wrap.
+func as[T any](p Process) (T, bool) {
+	for p != nil {
+		if t, ok := p.(T); ok {
+			return t, true
+		}
+
+		u, ok := p.(interface{ Unwrap() Process })
+		if !ok {
+			break
+		}
+
+		p = u.Unwrap()
+	}
+
+	var zero T
+	return zero, false
+}
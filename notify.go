@@ -0,0 +1,121 @@
+package parallel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const notifyTimeout = 10 * time.Second
+
+// Notifier is told about process failures and shutdowns.
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// WithNotifier delivers failure, quarantine, canary rollback and shutdown
+// events to n in the background. ThenStop waits for pending notifications
+// before returning; later ones, about processes failing after the shutdown,
+// are still delivered in the background.
+func WithNotifier(n Notifier) Option {
+	return func(c *Conductor) {
+		c.integrations = append(c.integrations, fmt.Sprintf("notifier:%T", n))
+		c.listeners = append(c.listeners, func(e Event) {
+			switch e.Type {
+			case EventProcessFailed, EventProcessStopFailed, EventProcessQuarantined, EventCanaryRolledBack,
+				EventShutdownStarted, EventShutdownComplete:
+			default:
+				return
+			}
+
+			c.notifications.add()
+			go func() {
+				defer c.notifications.done()
+
+				ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+				defer cancel()
+
+				if err := n.Notify(ctx, e); err != nil {
+					c.log.Error("failed to deliver notification", "event", string(e.Type), "error", err)
+				}
+			}()
+		})
+	}
+}
+
+type Webhook struct {
+	URLs    []string
+	Client  *http.Client
+	Retries int
+	Backoff time.Duration
+}
+
+// NewWebhook returns a Notifier that POSTs each event as JSON to every URL,
+// retrying failed deliveries three times with exponential backoff.
+func NewWebhook(urls ...string) *Webhook {
+	return &Webhook{
+		URLs:    urls,
+		Client:  http.DefaultClient,
+		Retries: 3,
+		Backoff: 500 * time.Millisecond,
+	}
+}
+
+func (w *Webhook) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, url := range w.URLs {
+		if err := w.post(ctx, url, body); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", url, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (w *Webhook) post(ctx context.Context, url string, body []byte) error {
+	backoff := w.Backoff
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = w.send(ctx, url, body); err == nil || attempt >= w.Retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
+
+func (w *Webhook) send(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package parallel_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/franklad/parallel"
+	"github.com/franklad/parallel/conductortest"
+)
+
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []parallel.EventType
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, e parallel.Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.events = append(n.events, e.Type)
+	return nil
+}
+
+func (n *recordingNotifier) types() []parallel.EventType {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return slices.Clone(n.events)
+}
+
+func TestNotifierQuarantineAndRollback(t *testing.T) {
+	n := &recordingNotifier{}
+	broken := parallel.Task("broken", func(ctx context.Context) error {
+		return parallel.Fatal(errors.New("bad config"))
+	})
+
+	c := conductortest.New(broken, blocking("api", nil)).With(
+		parallel.WithLogger(discard()),
+		parallel.WithQuarantine(parallel.Quarantine{Failures: 3}),
+		parallel.WithNotifier(n),
+	)
+	c.Run(context.Background())
+
+	waitFor(t, "the quarantine", func() bool {
+		return slices.Contains(n.types(), parallel.EventProcessQuarantined)
+	})
+
+	canary := parallel.Task("api", func(ctx context.Context) error { return errors.New("canary broken") })
+	if err := c.Canary(context.Background(), "api", canary, parallel.CanaryOptions{Duration: time.Second}); err == nil {
+		t.Fatal("broken canary was promoted")
+	}
+
+	c.Shutdown("test")
+	if err := c.ThenStop(); err != nil {
+		t.Fatalf("ThenStop: %v", err)
+	}
+
+	for _, want := range []parallel.EventType{parallel.EventProcessQuarantined, parallel.EventCanaryRolledBack, parallel.EventShutdownComplete} {
+		if !slices.Contains(n.types(), want) {
+			t.Errorf("notifier was not told about %s; got %v", want, n.types())
+		}
+	}
+}
+
+type slowNotifier struct {
+	recordingNotifier
+	delay time.Duration
+}
+
+func (n *slowNotifier) Notify(ctx context.Context, e parallel.Event) error {
+	time.Sleep(n.delay)
+	return n.recordingNotifier.Notify(ctx, e)
+}
+
+func TestNotifierLateFailures(t *testing.T) {
+	n := &slowNotifier{delay: 20 * time.Millisecond}
+	for range 2 {
+		var processes []parallel.Process
+		for i := range 5 {
+			processes = append(processes, newLateFailer(fmt.Sprint("p", i), time.Duration(i)*30*time.Millisecond, nil))
+		}
+
+		c := conductortest.New(processes...).With(
+			parallel.WithLogger(discard()),
+			parallel.WithNotifier(n),
+			parallel.WithShutdownSignal(syscall.SIGTERM, parallel.ShutdownPolicy{Timeout: 50 * time.Millisecond}),
+		)
+
+		c.Run(context.Background())
+		c.Shutdown("test")
+		if err := c.ThenStop(); err != nil {
+			t.Fatalf("ThenStop: %v", err)
+		}
+
+		if !slices.Contains(n.types(), parallel.EventShutdownComplete) {
+			t.Fatal("ThenStop returned before the shutdown notification was delivered")
+		}
+	}
+
+	// Late failures are still notified after ThenStop returned.
+	waitFor(t, "the late failures", func() bool {
+		var failed int
+		for _, typ := range n.types() {
+			if typ == parallel.EventProcessFailed {
+				failed++
+			}
+		}
+
+		return failed == 10
+	})
+}
@@ -0,0 +1,129 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+)
+
+// RunGuard records which one-shot processes already ran, so that a process
+// registered in every replica runs once per rollout. Acquire reports
+// whether key still has to run and, if so, claims it; Release records the
+// outcome of the claimed run, marking key as done when err is nil and
+// giving up the claim otherwise so that a later attempt may run it.
+type RunGuard interface {
+	Acquire(ctx context.Context, key string) (bool, error)
+	Release(ctx context.Context, key string, err error) error
+}
+
+// FileRunGuard is a RunGuard keeping a marker file per key in Dir. A run
+// holds a lock file until it is released; a lock left behind by a crashed
+// instance must be removed by hand. Replicas only share the guard when Dir
+// is on a shared volume; use a RunGuard backed by a database or lock
+// service otherwise.
+type FileRunGuard struct {
+	Dir string
+}
+
+func (g FileRunGuard) Acquire(ctx context.Context, key string) (bool, error) {
+	done, lock := g.paths(key)
+	if _, err := os.Stat(done); err == nil {
+		return false, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	if err := os.MkdirAll(g.Dir, 0o755); err != nil {
+		return false, err
+	}
+
+	f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	fmt.Fprintln(f, os.Getpid())
+	return true, f.Close()
+}
+
+func (g FileRunGuard) Release(ctx context.Context, key string, err error) error {
+	done, lock := g.paths(key)
+	if err == nil {
+		if err := os.WriteFile(done, nil, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return os.Remove(lock)
+}
+
+func (g FileRunGuard) paths(key string) (done, lock string) {
+	base := filepath.Join(g.Dir, url.PathEscape(key))
+	return base + ".done", base + ".lock"
+}
+
+type onceProcess struct {
+	Process
+	guard RunGuard
+}
+
+// RunOnce guards the one-shot process p, such as a database migration, with
+// guard, keyed by the name and version of p. Where p reports no version
+// the VCS revision or module version of the binary is used. A run whose
+// key already ran, or is being run by another instance, exits straight
+// away without running p.
+func RunOnce(p Process, guard RunGuard) Process {
+	return &onceProcess{
+		Process: p,
+		guard:   guard,
+	}
+}
+
+func (o *onceProcess) Run(ctx context.Context) error {
+	key := o.Name() + "@" + o.version()
+
+	run, err := o.guard.Acquire(ctx, key)
+	if err != nil {
+		return fmt.Errorf("run guard: %w", err)
+	}
+
+	if !run {
+		return nil
+	}
+
+	err = o.Process.Run(ctx)
+	if rerr := o.guard.Release(context.WithoutCancel(ctx), key, err); rerr != nil {
+		return errors.Join(err, fmt.Errorf("run guard: %w", rerr))
+	}
+
+	return err
+}
+
+func (o *onceProcess) version() string {
+	if v := versionOf(o.Process); v != "" {
+		return v
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	for _, s := range bi.Settings {
+		if s.Key == "vcs.revision" {
+			return s.Value
+		}
+	}
+
+	return bi.Main.Version
+}
+
+func (o *onceProcess) Unwrap() Process {
+	return o.Process
+}
@@ -0,0 +1,23 @@
+package parallel
+
+type Option func(*Conductor)
+
+// With applies configuration options to the conductor. Options should be
+// applied before Run.
+func (c *Conductor) With(opts ...Option) *Conductor {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, opt := range opts {
+		before := len(c.entries)
+		opt(c)
+
+		for _, e := range c.entries[before:] {
+			e.builtin = true
+		}
+	}
+
+	c.options = append(c.options, opts...)
+
+	return c
+}
@@ -0,0 +1,11 @@
+module github.com/franklad/parallel/otelmetrics
+
+go 1.22
+
+require (
+	github.com/franklad/parallel v0.0.0-20261014054952-24fb49c0595f
+	go.opentelemetry.io/otel v1.31.0
+	go.opentelemetry.io/otel/metric v1.31.0
+)
+
+require golang.org/x/sys v0.12.0 // indirect
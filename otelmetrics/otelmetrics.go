@@ -0,0 +1,96 @@
+package otelmetrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/franklad/parallel"
+)
+
+const scope = "github.com/franklad/parallel"
+
+var _ parallel.MetricsSink = (*Sink)(nil)
+
+type Sink struct {
+	meter metric.Meter
+
+	mu         sync.Mutex
+	gauges     map[string]metric.Float64Gauge
+	counters   map[string]metric.Int64Counter
+	histograms map[string]metric.Float64Histogram
+}
+
+// New returns a parallel.MetricsSink recording through a meter obtained
+// from mp. Timings are recorded as histograms in seconds.
+func New(mp metric.MeterProvider) *Sink {
+	return &Sink{
+		meter:      mp.Meter(scope),
+		gauges:     make(map[string]metric.Float64Gauge),
+		counters:   make(map[string]metric.Int64Counter),
+		histograms: make(map[string]metric.Float64Histogram),
+	}
+}
+
+// WithMeterProvider configures the conductor to emit its metrics through mp.
+func WithMeterProvider(mp metric.MeterProvider) parallel.Option {
+	return parallel.WithMetrics(New(mp))
+}
+
+func (s *Sink) Gauge(name string, value float64, tags ...parallel.Tag) {
+	s.mu.Lock()
+	g, ok := s.gauges[name]
+	if !ok {
+		var err error
+		if g, err = s.meter.Float64Gauge(name); err != nil {
+			g = noop.Float64Gauge{}
+		}
+		s.gauges[name] = g
+	}
+	s.mu.Unlock()
+
+	g.Record(context.Background(), value, attributes(tags))
+}
+
+func (s *Sink) Count(name string, delta int64, tags ...parallel.Tag) {
+	s.mu.Lock()
+	c, ok := s.counters[name]
+	if !ok {
+		var err error
+		if c, err = s.meter.Int64Counter(name); err != nil {
+			c = noop.Int64Counter{}
+		}
+		s.counters[name] = c
+	}
+	s.mu.Unlock()
+
+	c.Add(context.Background(), delta, attributes(tags))
+}
+
+func (s *Sink) Timing(name string, d time.Duration, tags ...parallel.Tag) {
+	s.mu.Lock()
+	h, ok := s.histograms[name]
+	if !ok {
+		var err error
+		if h, err = s.meter.Float64Histogram(name, metric.WithUnit("s")); err != nil {
+			h = noop.Float64Histogram{}
+		}
+		s.histograms[name] = h
+	}
+	s.mu.Unlock()
+
+	h.Record(context.Background(), d.Seconds(), attributes(tags))
+}
+
+func attributes(tags []parallel.Tag) metric.MeasurementOption {
+	kv := make([]attribute.KeyValue, len(tags))
+	for i, t := range tags {
+		kv[i] = attribute.String(t.Key, t.Value)
+	}
+
+	return metric.WithAttributes(kv...)
+}
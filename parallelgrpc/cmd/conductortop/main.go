@@ -0,0 +1,315 @@
+// Command conductortop is a top-like terminal viewer for services that
+// serve the parallel control API. It shows the live state, restart count
+// and labels of every process and the most recent errors, and restarts or
+// stops the selected process on request.
+//
+//	conductortop -addr unix:///run/myservice/control.sock
+//
+// When the control API requires a token, pass it with -token or the
+// PARALLEL_CONTROL_TOKEN environment variable.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/franklad/parallel/parallelgrpc/controlpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// recentErrors is the number of errors kept on screen.
+const recentErrors = 8
+
+type snapshot struct {
+	status    *controlpb.GetStatusResponse
+	processes []*controlpb.Process
+	err       error
+}
+
+type viewer struct {
+	client controlpb.ControlClient
+	addr   string
+	out    io.Writer
+
+	snapshot snapshot
+	errors   []*controlpb.Event
+	selected int
+	message  string
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:9090", "control API address, such as host:port or unix:///path/to/socket")
+	interval := flag.Duration("interval", time.Second, "refresh interval")
+	token := flag.String("token", os.Getenv("PARALLEL_CONTROL_TOKEN"), "bearer token for the control API")
+	flag.Parse()
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if *token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(*token)))
+	}
+
+	conn, err := grpc.NewClient(*addr, opts...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "conductortop:", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	restore, err := rawTerminal(os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "conductortop:", err)
+		os.Exit(1)
+	}
+	defer restore()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	v := &viewer{client: controlpb.NewControlClient(conn), addr: *addr, out: os.Stdout}
+	v.run(ctx, *interval)
+
+	fmt.Fprint(os.Stdout, "\x1b[?25h\x1b[2J\x1b[H")
+}
+
+// bearerToken sends a token in the authorization metadata of every call.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (bearerToken) RequireTransportSecurity() bool {
+	return false
+}
+
+func (v *viewer) run(ctx context.Context, interval time.Duration) {
+	keys := make(chan byte)
+	go readKeys(os.Stdin, keys)
+
+	events := make(chan *controlpb.Event)
+	go v.streamEvents(ctx, events)
+
+	snapshots := make(chan snapshot, 1)
+	results := make(chan string, 1)
+	refresh := func() { go func() { snapshots <- v.fetch(ctx) }() }
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	refresh()
+	for {
+		v.render()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refresh()
+		case s := <-snapshots:
+			v.snapshot = s
+			v.selected = min(v.selected, max(len(s.processes)-1, 0))
+		case e := <-events:
+			if e.Error != "" {
+				v.errors = append(v.errors, e)
+				if len(v.errors) > recentErrors {
+					v.errors = v.errors[1:]
+				}
+			}
+
+			refresh()
+		case msg := <-results:
+			v.message = msg
+			refresh()
+		case key := <-keys:
+			switch key {
+			case 'q', 3: // q or Ctrl+C
+				return
+			case 'k', 'A': // k or the up arrow
+				v.selected = max(v.selected-1, 0)
+			case 'j', 'B': // j or the down arrow
+				v.selected = min(v.selected+1, max(len(v.snapshot.processes)-1, 0))
+			case 'r':
+				if name := v.selectedName(); name != "" {
+					v.message = "restarting " + name + "..."
+					go func() { results <- v.restart(ctx, name) }()
+				}
+			case 's':
+				if name := v.selectedName(); name != "" {
+					v.message = "stopping " + name + "..."
+					go func() { results <- v.stop(ctx, name) }()
+				}
+			}
+		}
+	}
+}
+
+func (v *viewer) fetch(ctx context.Context) snapshot {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	status, err := v.client.GetStatus(ctx, &controlpb.GetStatusRequest{})
+	if err != nil {
+		return snapshot{err: err}
+	}
+
+	list, err := v.client.ListProcesses(ctx, &controlpb.ListProcessesRequest{})
+	if err != nil {
+		return snapshot{err: err}
+	}
+
+	return snapshot{status: status, processes: list.Processes}
+}
+
+// streamEvents forwards lifecycle events to events, reconnecting after a
+// second when the stream breaks.
+func (v *viewer) streamEvents(ctx context.Context, events chan<- *controlpb.Event) {
+	for ctx.Err() == nil {
+		stream, err := v.client.StreamEvents(ctx, &controlpb.StreamEventsRequest{})
+		for err == nil {
+			var e *controlpb.Event
+			if e, err = stream.Recv(); err == nil {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+		}
+	}
+}
+
+func (v *viewer) restart(ctx context.Context, name string) string {
+	if _, err := v.client.Restart(ctx, &controlpb.RestartRequest{Name: name}); err != nil {
+		return "restart " + name + ": " + err.Error()
+	}
+
+	return "restarted " + name
+}
+
+func (v *viewer) stop(ctx context.Context, name string) string {
+	if _, err := v.client.Stop(ctx, &controlpb.StopRequest{Name: name}); err != nil {
+		return "stop " + name + ": " + err.Error()
+	}
+
+	return "stopped " + name
+}
+
+func (v *viewer) selectedName() string {
+	if v.selected >= len(v.snapshot.processes) {
+		return ""
+	}
+
+	return v.snapshot.processes[v.selected].Name
+}
+
+func (v *viewer) render() {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		fmt.Fprintf(&b, format+"\x1b[K\r\n", args...)
+	}
+
+	b.WriteString("\x1b[?25l\x1b[H")
+
+	switch s := v.snapshot; {
+	case s.err != nil:
+		line("\x1b[1m%s\x1b[0m  \x1b[31munreachable: %v\x1b[0m", v.addr, s.err)
+	case s.status == nil:
+		line("\x1b[1m%s\x1b[0m  connecting...", v.addr)
+	default:
+		status := s.status.Status
+		if s.status.Draining {
+			status += " (draining)"
+		}
+
+		line("\x1b[1m%s\x1b[0m  %s  %d processes", v.addr, colorize(status, s.status.Serving), len(s.processes))
+	}
+
+	line("")
+	line("\x1b[7m  %-24s %-12s %-12s %8s  %-30s\x1b[0m", "PROCESS", "STATE", "VERSION", "RESTARTS", "LABELS")
+	for i, p := range v.snapshot.processes {
+		cursor := " "
+		if i == v.selected {
+			cursor = ">"
+		}
+
+		state := p.State
+		if p.Quarantined {
+			state += "*"
+		}
+
+		line("%s %-24s %-12s %-12s %8d  %-30s", cursor, truncate(p.Name, 24), state, truncate(p.Version, 12), p.Restarts, truncate(labels(p), 30))
+	}
+
+	line("")
+	line("\x1b[1mRecent errors\x1b[0m")
+	if len(v.errors) == 0 {
+		line("  none")
+	}
+
+	for i := len(v.errors) - 1; i >= 0; i-- {
+		e := v.errors[i]
+		line("  %s  %-24s %s", e.Time.AsTime().Local().Format(time.TimeOnly), truncate(e.Process, 24), e.Error)
+	}
+
+	line("")
+	line("%s", v.message)
+	line("\x1b[2mup/down select  r restart  s stop  q quit   * quarantined\x1b[0m")
+	b.WriteString("\x1b[J")
+
+	io.WriteString(v.out, b.String())
+}
+
+func colorize(status string, serving bool) string {
+	if serving {
+		return "\x1b[32m" + status + "\x1b[0m"
+	}
+
+	return "\x1b[33m" + status + "\x1b[0m"
+}
+
+func labels(p *controlpb.Process) string {
+	var parts []string
+	for k, v := range p.Labels {
+		if k != "process" {
+			parts = append(parts, k+"="+v)
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n-1] + "~"
+}
+
+// readKeys sends every byte read from r to keys. Arrow keys arrive as
+// ESC [ A and ESC [ B, so their final byte doubles as the key.
+func readKeys(r io.Reader, keys chan<- byte) {
+	buf := make([]byte, 16)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for _, b := range buf[:n] {
+			keys <- b
+		}
+	}
+}
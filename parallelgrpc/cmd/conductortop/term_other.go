@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package main
+
+import "os"
+
+// rawTerminal leaves the terminal as it is, so keys take effect after Enter.
+func rawTerminal(*os.File) (func(), error) {
+	return func() {}, nil
+}
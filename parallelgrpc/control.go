@@ -0,0 +1,250 @@
+package parallelgrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/franklad/parallel"
+	"github.com/franklad/parallel/parallelgrpc/controlpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpccreds "google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// eventBuffer is the number of events buffered for each StreamEvents call;
+// events are dropped for streams that fall further behind.
+const eventBuffer = 64
+
+type controlServer struct {
+	controlpb.UnimplementedControlServer
+
+	conductor *parallel.Conductor
+}
+
+// RegisterControl registers the parallel.control.v1 Control service on s so
+// that remote tooling can list, restart and stop the processes of c, shut it
+// down and watch its lifecycle events. Clients are created with
+// controlpb.NewControlClient. With parallel.WithAuthenticator, every call is
+// authenticated from its "authorization: Bearer" metadata or its client
+// certificate, and Restart, Stop and Shutdown require parallel.RoleOperate.
+func RegisterControl(s grpc.ServiceRegistrar, c *parallel.Conductor) {
+	controlpb.RegisterControlServer(s, &controlServer{conductor: c})
+}
+
+func (s *controlServer) ListProcesses(ctx context.Context, _ *controlpb.ListProcessesRequest) (*controlpb.ListProcessesResponse, error) {
+	if _, err := s.authorize(ctx, parallel.RoleReadOnly); err != nil {
+		return nil, err
+	}
+
+	handles := s.conductor.Processes()
+	resp := &controlpb.ListProcessesResponse{Processes: make([]*controlpb.Process, len(handles))}
+	for i, h := range handles {
+		resp.Processes[i] = process(h)
+	}
+
+	return resp, nil
+}
+
+func (s *controlServer) GetStatus(ctx context.Context, _ *controlpb.GetStatusRequest) (*controlpb.GetStatusResponse, error) {
+	if _, err := s.authorize(ctx, parallel.RoleReadOnly); err != nil {
+		return nil, err
+	}
+
+	st := s.conductor.Status()
+	return &controlpb.GetStatusResponse{
+		Status:   string(st),
+		Serving:  st.Serving(),
+		Draining: s.conductor.Draining(),
+	}, nil
+}
+
+func (s *controlServer) Restart(ctx context.Context, req *controlpb.RestartRequest) (*controlpb.RestartResponse, error) {
+	ctx, err := s.authorize(ctx, parallel.RoleOperate)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := s.lookup(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.conductor.Restart(ctx, h.Name()); err != nil {
+		return nil, controlError(err)
+	}
+
+	return &controlpb.RestartResponse{Process: process(h)}, nil
+}
+
+func (s *controlServer) Stop(ctx context.Context, req *controlpb.StopRequest) (*controlpb.StopResponse, error) {
+	ctx, err := s.authorize(ctx, parallel.RoleOperate)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := s.lookup(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.Stop(ctx); err != nil {
+		return nil, controlError(err)
+	}
+
+	return &controlpb.StopResponse{Process: process(h)}, nil
+}
+
+func (s *controlServer) Shutdown(ctx context.Context, req *controlpb.ShutdownRequest) (*controlpb.ShutdownResponse, error) {
+	ctx, err := s.authorize(ctx, parallel.RoleOperate)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := "control: shutdown requested"
+	if req.GetReason() != "" {
+		reason = "control: " + req.GetReason()
+	}
+
+	s.conductor.Audit(ctx, "shutdown", reason, nil)
+	s.conductor.Shutdown(reason)
+	return &controlpb.ShutdownResponse{}, nil
+}
+
+func (s *controlServer) StreamEvents(req *controlpb.StreamEventsRequest, stream grpc.ServerStreamingServer[controlpb.Event]) error {
+	if _, err := s.authorize(stream.Context(), parallel.RoleReadOnly); err != nil {
+		return err
+	}
+
+	events, cancel := s.conductor.Subscribe(eventBuffer)
+	defer cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e := <-events:
+			if e.Process != "" && len(req.Processes) > 0 && !slices.Contains(req.Processes, e.Process) {
+				continue
+			}
+
+			if err := stream.Send(event(e)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func event(e parallel.Event) *controlpb.Event {
+	msg := &controlpb.Event{
+		Type:    string(e.Type),
+		Process: e.Process,
+		Time:    timestamppb.New(e.Time),
+	}
+
+	if e.Duration > 0 {
+		msg.Duration = durationpb.New(e.Duration)
+	}
+
+	if e.Err != nil {
+		msg.Error = e.Err.Error()
+	}
+
+	if e.Panic != nil {
+		msg.Panic = fmt.Sprint(e.Panic)
+	}
+
+	return msg
+}
+
+// authorize checks the caller's credentials against the conductor's
+// authenticator, returning ctx attributing its actions to the caller.
+func (s *controlServer) authorize(ctx context.Context, need parallel.Role) (context.Context, error) {
+	ctx, err := s.conductor.Authorize(ctx, credentials(ctx), need)
+	switch {
+	case errors.Is(err, parallel.ErrForbidden):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Unauthenticated, parallel.ErrUnauthenticated.Error())
+	}
+
+	return actor(ctx), nil
+}
+
+// credentials returns the bearer token in the call's authorization
+// metadata and the client certificates verified by its TLS handshake.
+func credentials(ctx context.Context) parallel.Credentials {
+	var creds parallel.Credentials
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, v := range md.Get("authorization") {
+			if token, ok := strings.CutPrefix(v, "Bearer "); ok {
+				creds.Token = strings.TrimSpace(token)
+			}
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(grpccreds.TLSInfo); ok {
+			creds.VerifiedChains = info.State.VerifiedChains
+		}
+	}
+
+	return creds
+}
+
+// actor attributes the control actions taken with ctx to the calling peer,
+// unless the caller was authenticated or an interceptor has already set an
+// actor.
+func actor(ctx context.Context) context.Context {
+	if parallel.ActorFrom(ctx) != "" {
+		return ctx
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		return parallel.WithActor(ctx, "grpc:"+p.Addr.String())
+	}
+
+	return ctx
+}
+
+func (s *controlServer) lookup(name string) (*parallel.Handle, error) {
+	h, ok := s.conductor.Lookup(name)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown process %q", name)
+	}
+
+	return h, nil
+}
+
+func process(h *parallel.Handle) *controlpb.Process {
+	return &controlpb.Process{
+		Name:        h.Name(),
+		State:       string(h.State()),
+		Restarts:    int32(h.Restarts()),
+		Quarantined: h.Quarantined(),
+		Labels:      h.Labels(),
+		Version:     h.Version(),
+	}
+}
+
+// controlError maps conductor errors to gRPC status errors.
+func controlError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, parallel.ErrNotRunning),
+		errors.Is(err, parallel.ErrProcessBusy),
+		errors.Is(err, parallel.ErrProcessSkipped),
+		errors.Is(err, parallel.ErrQuarantined):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,5 @@
+// Package controlpb contains the generated protobuf messages, client and
+// server interfaces of the conductor control API defined in control.proto.
+package controlpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative control.proto
@@ -0,0 +1,53 @@
+// Package parallelgrpc connects a parallel.Conductor to gRPC servers. It is
+// a separate module so that the core package does not depend on gRPC.
+package parallelgrpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/franklad/parallel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// healthService is left ungated so that health checks can report the
+// conductor's state themselves.
+const healthService = "/grpc.health.v1.Health/"
+
+func gate(c *parallel.Conductor, method string) error {
+	if strings.HasPrefix(method, healthService) {
+		return nil
+	}
+
+	if s := c.Status(); !s.Serving() {
+		return status.Error(codes.Unavailable, "service is "+string(s))
+	}
+
+	return nil
+}
+
+// UnaryServerInterceptor fails calls with UNAVAILABLE unless the
+// conductor's status is healthy or degraded.
+func UnaryServerInterceptor(c *parallel.Conductor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := gate(c, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func StreamServerInterceptor(c *parallel.Conductor) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := gate(c, info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
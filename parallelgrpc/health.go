@@ -0,0 +1,59 @@
+package parallelgrpc
+
+import (
+	"github.com/franklad/parallel"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Service maps a gRPC service name to the processes that must be running
+// for it to be serving.
+type Service struct {
+	Name      string
+	Processes []string
+}
+
+// RegisterHealth registers the standard grpc.health.v1 Health service on s
+// and keeps it in step with c. The overall service "" is serving while the
+// conductor is healthy or degraded, every process is reported under its own
+// name as serving while it is running, and each of services is serving
+// while the overall service and all of its processes are. Statuses are
+// updated on every lifecycle event.
+func RegisterHealth(s grpc.ServiceRegistrar, c *parallel.Conductor, services ...Service) *health.Server {
+	h := health.NewServer()
+	healthpb.RegisterHealthServer(s, h)
+
+	update := func() {
+		serving := c.Status().Serving()
+		h.SetServingStatus("", servingStatus(serving))
+
+		running := make(map[string]bool)
+		for _, p := range c.Processes() {
+			running[p.Name()] = p.State() == parallel.ProcessRunning
+			h.SetServingStatus(p.Name(), servingStatus(serving && running[p.Name()]))
+		}
+
+		for _, svc := range services {
+			ok := serving
+			for _, name := range svc.Processes {
+				ok = ok && running[name]
+			}
+
+			h.SetServingStatus(svc.Name, servingStatus(ok))
+		}
+	}
+
+	update()
+	c.With(parallel.WithEventListener(func(parallel.Event) { update() }))
+
+	return h
+}
+
+func servingStatus(ok bool) healthpb.HealthCheckResponse_ServingStatus {
+	if ok {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
@@ -0,0 +1,54 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// periodic is a Process that calls tick every interval until stopped.
+type periodic struct {
+	name     string
+	interval time.Duration
+	tick     func(ctx context.Context)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func (p *periodic) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	p.mu.Lock()
+	p.cancel = cancel
+	p.mu.Unlock()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *periodic) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cancel != nil {
+		p.cancel()
+	}
+
+	return nil
+}
+
+func (p *periodic) Name() string {
+	return p.name
+}
@@ -0,0 +1,287 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+)
+
+// ErrSkip is returned by a Transform to drop the item instead of passing a
+// result on.
+var ErrSkip = errors.New("skip item")
+
+// Stage is a step of a Pipeline, created with Source, Transform or Sink.
+type Stage struct {
+	name    string
+	workers int
+	buffer  int
+	in, out reflect.Type
+
+	source    func(ctx context.Context, emit func(any) error) error
+	transform func(ctx context.Context, v any) (any, error)
+}
+
+// Source returns the first stage of a pipeline. fn produces the items by
+// calling emit, which blocks while the next stage is busy and fails once
+// the pipeline is stopping. Returning ends the pipeline once the items
+// already emitted have made their way through it.
+func Source[Out any](name string, fn func(ctx context.Context, emit func(Out) error) error) Stage {
+	return Stage{
+		name:    name,
+		workers: 1,
+		out:     reflect.TypeFor[Out](),
+		source: func(ctx context.Context, emit func(any) error) error {
+			return fn(ctx, func(v Out) error { return emit(v) })
+		},
+	}
+}
+
+// Transform returns a stage passing fn's result for each item on to the
+// next stage. An error other than ErrSkip fails the pipeline.
+func Transform[In, Out any](name string, fn func(ctx context.Context, in In) (Out, error)) Stage {
+	return Stage{
+		name:    name,
+		workers: 1,
+		in:      reflect.TypeFor[In](),
+		out:     reflect.TypeFor[Out](),
+		transform: func(ctx context.Context, v any) (any, error) {
+			in, _ := v.(In)
+			return fn(ctx, in)
+		},
+	}
+}
+
+// Sink returns the last stage of a pipeline, consuming each item with fn.
+// An error fails the pipeline.
+func Sink[In any](name string, fn func(ctx context.Context, in In) error) Stage {
+	return Stage{
+		name:    name,
+		workers: 1,
+		in:      reflect.TypeFor[In](),
+		transform: func(ctx context.Context, v any) (any, error) {
+			in, _ := v.(In)
+			return nil, fn(ctx, in)
+		},
+	}
+}
+
+// Workers returns s processing up to n items at once. Sources always run
+// on one goroutine.
+func (s Stage) Workers(n int) Stage {
+	if s.source == nil {
+		s.workers = max(n, 1)
+	}
+
+	return s
+}
+
+// Buffer returns s accepting up to n items ahead of its workers.
+func (s Stage) Buffer(n int) Stage {
+	s.buffer = max(n, 0)
+	return s
+}
+
+type pipeline struct {
+	name   string
+	stages []Stage
+
+	mu      sync.Mutex
+	stopSrc context.CancelFunc
+	abort   context.CancelCauseFunc
+	done    chan struct{}
+}
+
+// Pipeline returns a Process called name that runs stages as a chain
+// connected by bounded channels: a Source, any number of Transforms and a
+// Sink. Stop stops the source and drains the stages in order, each
+// finishing the items it holds before the next one does; items still in
+// the pipeline when the stop context is done are abandoned.
+func Pipeline(name string, stages ...Stage) Process {
+	return &pipeline{name: name, stages: stages}
+}
+
+func (p *pipeline) Name() string {
+	return p.name
+}
+
+// validate checks that the stages form a source-to-sink chain whose item
+// types match.
+func (p *pipeline) validate() error {
+	if len(p.stages) < 2 {
+		return errors.New("pipeline needs a source and a sink")
+	}
+
+	for i, s := range p.stages {
+		switch {
+		case i == 0 && s.source == nil:
+			return fmt.Errorf("first stage %s is not a source", s.name)
+		case i > 0 && s.source != nil:
+			return fmt.Errorf("stage %s: a source must be the first stage", s.name)
+		case i < len(p.stages)-1 && s.out == nil:
+			return fmt.Errorf("stage %s: a sink must be the last stage", s.name)
+		case i == len(p.stages)-1 && s.out != nil:
+			return fmt.Errorf("last stage %s is not a sink", s.name)
+		}
+
+		if i > 0 {
+			prev := p.stages[i-1]
+			if !prev.out.AssignableTo(s.in) {
+				return fmt.Errorf("stage %s takes %s but %s emits %s", s.name, s.in, prev.name, prev.out)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *pipeline) Run(ctx context.Context) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+
+	ctx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
+
+	srcCtx, stopSrc := context.WithCancel(ctx)
+	defer stopSrc()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	p.mu.Lock()
+	p.stopSrc, p.abort, p.done = stopSrc, abort, done
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+
+	src, first := p.stages[0], make(chan any, p.stages[1].buffer)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(first)
+
+		emit := func(v any) error {
+			select {
+			case first <- v:
+				return nil
+			case <-srcCtx.Done():
+				return srcCtx.Err()
+			}
+		}
+
+		if err := runSource(srcCtx, src, emit); err != nil && srcCtx.Err() == nil {
+			abort(fmt.Errorf("stage %s: %w", src.name, err))
+		}
+	}()
+
+	in := first
+	for i, s := range p.stages[1:] {
+		var out chan any
+		if i+2 < len(p.stages) {
+			out = make(chan any, p.stages[i+2].buffer)
+		}
+
+		p.start(ctx, s, in, out, abort, &wg)
+		in = out
+	}
+
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return nil
+}
+
+// start runs the workers of s, which take items from in and pass their
+// results to out, closing out once they are all done. Out is nil for the
+// sink.
+func (p *pipeline) start(ctx context.Context, s Stage, in <-chan any, out chan any, abort context.CancelCauseFunc, wg *sync.WaitGroup) {
+	var workers sync.WaitGroup
+	for range s.workers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+
+			for v := range in {
+				if ctx.Err() != nil {
+					return
+				}
+
+				r, err := runStage(ctx, s, v)
+				switch {
+				case errors.Is(err, ErrSkip):
+					continue
+				case err != nil:
+					abort(fmt.Errorf("stage %s: %w", s.name, err))
+					return
+				case out == nil:
+					continue
+				}
+
+				select {
+				case out <- r:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		workers.Wait()
+		if out != nil {
+			close(out)
+		}
+	}()
+}
+
+func runStage(ctx context.Context, s Stage, v any) (r any, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	return s.transform(ctx, v)
+}
+
+func runSource(ctx context.Context, s Stage, emit func(any) error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	return s.source(ctx, emit)
+}
+
+// Stop stops the source and waits for the stages to drain, abandoning the
+// remaining items once ctx is done.
+func (p *pipeline) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	stopSrc, abort, done := p.stopSrc, p.abort, p.done
+	p.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	stopSrc()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		abort(context.Canceled)
+		<-done
+		return ctx.Err()
+	}
+}
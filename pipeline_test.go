@@ -0,0 +1,80 @@
+package parallel_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/franklad/parallel"
+	"github.com/franklad/parallel/conductortest"
+)
+
+//go:noinline
+func explode(n int) int {
+	panic("boom")
+}
+
+func TestPipelinePanicKeepsStack(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages []parallel.Stage
+	}{
+		{
+			name: "transform",
+			stages: []parallel.Stage{
+				parallel.Source("numbers", func(ctx context.Context, emit func(int) error) error {
+					return emit(1)
+				}),
+				parallel.Transform("explode", func(ctx context.Context, n int) (int, error) {
+					return explode(n), nil
+				}),
+				parallel.Sink("discard", func(ctx context.Context, n int) error { return nil }),
+			},
+		},
+		{
+			name: "source",
+			stages: []parallel.Stage{
+				parallel.Source("numbers", func(ctx context.Context, emit func(int) error) error {
+					return emit(explode(1))
+				}),
+				parallel.Sink("discard", func(ctx context.Context, n int) error { return nil }),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := make(chan *parallel.Error, 1)
+			c := conductortest.New(parallel.Pipeline("pipeline", tt.stages...)).With(
+				parallel.WithLogger(discard()),
+				parallel.WithEventListener(func(e parallel.Event) {
+					if e.Type != parallel.EventProcessFailed {
+						return
+					}
+
+					select {
+					case failures <- e.Err.(*parallel.Error):
+					default:
+					}
+				}),
+			)
+			c.Run(context.Background())
+			c.ThenStop()
+
+			var perr *parallel.Error
+			select {
+			case perr = <-failures:
+			default:
+				t.Fatal("pipeline did not fail")
+			}
+
+			if perr.Panic() != "boom" {
+				t.Errorf("Panic() = %v, want boom", perr.Panic())
+			}
+
+			if !strings.Contains(string(perr.Stack), "explode") {
+				t.Errorf("stack does not show the panic site:\n%s", perr.Stack)
+			}
+		})
+	}
+}
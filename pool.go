@@ -0,0 +1,535 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrQueueFull  = errors.New("pool queue is full")
+	ErrPoolClosed = errors.New("pool is closed")
+)
+
+const starvationLimit = 8
+
+// Job is a unit of work run by a Pool.
+type Job func(ctx context.Context) error
+
+// Priority is the priority of a job in a Pool. Workers take queued jobs of
+// a higher priority first.
+type Priority int
+
+const (
+	PriorityLow Priority = iota - 1
+	PriorityNormal
+	PriorityHigh
+)
+
+const priorities = 3
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// level returns the index of the queue of p.
+func (p Priority) level() int {
+	return int(p - PriorityLow)
+}
+
+// SubmitOption configures a single submission.
+type SubmitOption func(*queuedJob)
+
+// AtPriority submits the job at priority p instead of PriorityNormal.
+// Priorities beyond PriorityLow and PriorityHigh are clamped to them.
+func AtPriority(p Priority) SubmitOption {
+	return func(q *queuedJob) {
+		q.priority = min(max(p, PriorityLow), PriorityHigh)
+	}
+}
+
+// PoolOptions configures NewPool. Workers defaults to WorkersPerCPU, 1 by
+// default, times CPUs, so a pool inside a container sizes itself to the
+// container's CPU quota rather than the host. QueueSize, the capacity of
+// the queue of each priority, defaults to 0, in which case Submit only
+// succeeds once a worker is free. A worker that has taken
+// StarvationLimit jobs in a row while lower-priority jobs were waiting
+// takes a job of the lowest waiting priority next; it defaults to 8.
+// Metrics receives the pool's metrics, tagged with the pool name and, where
+// it applies, the priority; it defaults to the metrics sink of the
+// conductor running the pool. Autoscale, when set, varies the number of
+// workers with the load, starting from Workers.
+//
+// A failed job is retried Retries times, waiting RetryBackoff, 100ms by
+// default, before the first retry and twice as long before each further
+// one. A job that still fails is handed to DeadLetters, or logged when it
+// is nil. A job cancelled by Stop is recorded as cancelled instead.
+type PoolOptions struct {
+	Workers         int
+	WorkersPerCPU   float64
+	QueueSize       int
+	StarvationLimit int
+	Metrics         MetricsSink
+	Autoscale       *PoolAutoscale
+	Retries         int
+	RetryBackoff    time.Duration
+	DeadLetters     DeadLetterHandler
+}
+
+// Pool is a Process running submitted jobs on a number of workers.
+// Stop stops accepting jobs and waits for the queued ones to finish; jobs
+// still running when the stop context is done are cancelled.
+type Pool struct {
+	name string
+	opts PoolOptions
+
+	// mu is held for reading while jobs are submitted, and for writing
+	// to close or replace the queues.
+	mu      sync.RWMutex
+	queues  [priorities]chan queuedJob
+	closing chan struct{}
+	closed  bool
+
+	run    sync.Mutex
+	done   chan struct{}
+	cancel context.CancelFunc
+
+	conductor atomic.Pointer[Conductor]
+	workers   atomic.Int64
+	size      atomic.Int64
+	lastWait  atomic.Int64
+	shrink    chan struct{}
+
+	jobs     sync.Mutex
+	lastID   JobID
+	records  map[JobID]*jobRecord
+	finished []JobID
+}
+
+type queuedJob struct {
+	job       Job
+	priority  Priority
+	submitted time.Time
+	record    *jobRecord
+	payload   any
+	retries   int
+}
+
+// NewPool returns a pool called name. Jobs can be submitted before it
+// runs; they start once the conductor starts the pool.
+func NewPool(name string, opts PoolOptions) *Pool {
+	if opts.Workers <= 0 {
+		opts.Workers = CPUs()
+		if opts.WorkersPerCPU > 0 {
+			opts.Workers = max(int(math.Ceil(opts.WorkersPerCPU*float64(opts.Workers))), 1)
+		}
+	}
+
+	if opts.StarvationLimit <= 0 {
+		opts.StarvationLimit = starvationLimit
+	}
+
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = 100 * time.Millisecond
+	}
+
+	if a := opts.Autoscale; a != nil {
+		a.defaults()
+		opts.Workers = min(max(opts.Workers, a.MinWorkers), a.MaxWorkers)
+	}
+
+	p := &Pool{name: name, opts: opts, shrink: make(chan struct{})}
+	p.size.Store(int64(opts.Workers))
+	p.reset()
+	return p
+}
+
+// reset prepares the pool to accept jobs again. The caller must hold p.mu
+// or own p exclusively.
+func (p *Pool) reset() {
+	for i := range p.queues {
+		p.queues[i] = make(chan queuedJob, p.opts.QueueSize)
+	}
+
+	p.closing = make(chan struct{})
+	p.closed = false
+}
+
+func (p *Pool) Name() string {
+	return p.name
+}
+
+func (p *Pool) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Submitters blocked on a full queue hold p.mu for reading, so it is
+	// only locked for writing to replace closed queues.
+	p.mu.RLock()
+	queues, closed := p.queues, p.closed
+	p.mu.RUnlock()
+
+	if closed {
+		p.mu.Lock()
+		if p.closed {
+			p.reset()
+		}
+
+		queues = p.queues
+		p.mu.Unlock()
+	}
+
+	done := make(chan struct{})
+
+	p.run.Lock()
+	p.done, p.cancel = done, cancel
+	p.run.Unlock()
+
+	defer close(done)
+
+	p.conductor.Store(conductorFrom(ctx))
+
+	var wg sync.WaitGroup
+	p.workers.Store(0)
+	p.size.Store(int64(p.opts.Workers))
+	p.grow(ctx, queues, &wg, p.opts.Workers)
+
+	if p.opts.Autoscale != nil {
+		p.autoscale(ctx, queues, &wg)
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// resize sets the number of workers the next run starts with, within the
+// bounds of Autoscale.
+func (p *Pool) resize(n int) {
+	if a := p.opts.Autoscale; a != nil {
+		n = min(max(n, a.MinWorkers), a.MaxWorkers)
+	}
+
+	p.opts.Workers = max(n, 1)
+	p.size.Store(int64(p.opts.Workers))
+}
+
+// grow starts n more workers.
+func (p *Pool) grow(ctx context.Context, queues [priorities]chan queuedJob, wg *sync.WaitGroup, n int) {
+	for range n {
+		p.workers.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer p.workers.Add(-1)
+			p.work(ctx, queues)
+		}()
+	}
+
+	p.sink().Gauge("pool.workers", float64(p.workers.Load()), Tag{Key: "pool", Value: p.name})
+}
+
+// work runs the jobs of queues until they are closed and drained, or until
+// the worker is told to shrink the pool while idle.
+func (p *Pool) work(ctx context.Context, queues [priorities]chan queuedJob) {
+	w := worker{queues: queues, limit: p.opts.StarvationLimit, shrink: p.shrink}
+	for i, q := range queues {
+		w.open[i] = q
+	}
+
+	for {
+		q, ok := w.next()
+		if !ok {
+			return
+		}
+
+		tags := p.tags(q.priority)
+		p.sink().Gauge("pool.queue_depth", float64(len(queues[q.priority.level()])), tags...)
+
+		jobCtx, ok := p.begin(ctx, q)
+		if !ok {
+			continue
+		}
+
+		wait := time.Since(q.submitted)
+		p.lastWait.Store(int64(wait))
+		p.sink().Timing("pool.wait_time", wait, tags...)
+
+		started := time.Now()
+		attempts, err := p.attempt(jobCtx, q)
+		if err != nil {
+			p.sink().Count("pool.failures", 1, tags...)
+		}
+
+		p.sink().Timing("pool.run_time", time.Since(started), tags...)
+		if p.end(ctx, q, err, attempts) {
+			p.deadLetter(ctx, q, err, attempts)
+		}
+	}
+}
+
+// worker picks the jobs of one pool worker. open holds the queues that are
+// not yet closed and drained, nil otherwise.
+type worker struct {
+	queues [priorities]chan queuedJob
+	open   [priorities]chan queuedJob
+	shrink <-chan struct{}
+	limit  int
+	streak int
+}
+
+// next returns the next job to run, waiting for one if every queue is
+// empty, and false once every queue is closed and drained or the worker
+// is told to shrink the pool while waiting. Jobs of a
+// higher priority come first, except that after limit of them in a row
+// while lower-priority jobs were waiting, the lowest-priority waiting job
+// is taken.
+func (w *worker) next() (queuedJob, bool) {
+	if w.streak >= w.limit {
+		for level := range priorities {
+			if q, ok := w.take(level); ok {
+				w.streak = 0
+				return q, true
+			}
+		}
+	}
+
+	for level := priorities - 1; level >= 0; level-- {
+		if q, ok := w.take(level); ok {
+			w.streak++
+			if !w.waiting(level) {
+				w.streak = 0
+			}
+
+			return q, true
+		}
+	}
+
+	for w.open != [priorities]chan queuedJob{} {
+		var (
+			q     queuedJob
+			ok    bool
+			level int
+		)
+
+		select {
+		case q, ok = <-w.open[2]:
+			level = 2
+		case q, ok = <-w.open[1]:
+			level = 1
+		case q, ok = <-w.open[0]:
+			level = 0
+		case <-w.shrink:
+			return queuedJob{}, false
+		}
+
+		if ok {
+			return q, true
+		}
+
+		w.open[level] = nil
+	}
+
+	return queuedJob{}, false
+}
+
+// take receives a job from the queue of level without waiting.
+func (w *worker) take(level int) (queuedJob, bool) {
+	if w.open[level] == nil {
+		return queuedJob{}, false
+	}
+
+	select {
+	case q, ok := <-w.open[level]:
+		if !ok {
+			w.open[level] = nil
+		}
+
+		return q, ok
+	default:
+		return queuedJob{}, false
+	}
+}
+
+// waiting reports whether jobs below level are queued.
+func (w *worker) waiting(level int) bool {
+	for below := range level {
+		if len(w.queues[below]) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	return job(ctx)
+}
+
+// Stop stops accepting jobs and waits for the workers to finish the queued
+// ones, cancelling the jobs still running once ctx is done.
+func (p *Pool) Stop(ctx context.Context) error {
+	p.mu.RLock()
+	closing := p.closing
+	p.mu.RUnlock()
+
+	select {
+	case <-closing:
+	default:
+		close(closing)
+	}
+
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		for _, q := range p.queues {
+			close(q)
+		}
+	}
+	p.mu.Unlock()
+
+	p.run.Lock()
+	done, cancel := p.done, p.cancel
+	p.run.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Submit queues job, blocking until there is room in the queue, and returns
+// its ID. It fails with ctx's error once ctx is done, and with
+// ErrPoolClosed once the pool is stopping.
+func (p *Pool) Submit(ctx context.Context, job Job, opts ...SubmitOption) (JobID, error) {
+	return p.submit(ctx, job, opts)
+}
+
+// TrySubmit queues job if there is room in the queue right now and
+// otherwise fails with ErrQueueFull.
+func (p *Pool) TrySubmit(job Job, opts ...SubmitOption) (JobID, error) {
+	return p.submit(nil, job, opts)
+}
+
+// SubmitTimeout queues job, waiting at most d for room in the queue before
+// failing with ErrQueueFull.
+func (p *Pool) SubmitTimeout(job Job, d time.Duration, opts ...SubmitOption) (JobID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	id, err := p.submit(ctx, job, opts)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return 0, ErrQueueFull
+	}
+
+	return id, err
+}
+
+// submit queues job, waiting for room in the queue until ctx is done, or
+// not at all when ctx is nil.
+func (p *Pool) submit(ctx context.Context, job Job, opts []SubmitOption) (JobID, error) {
+	q := queuedJob{job: job, submitted: time.Now(), retries: p.opts.Retries}
+	for _, opt := range opts {
+		opt(&q)
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return 0, ErrPoolClosed
+	}
+
+	id := p.track(&q)
+	if err := p.enqueue(ctx, q); err != nil {
+		p.untrack(id)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// enqueue sends q to the queue of its priority. The caller must hold p.mu
+// for reading.
+func (p *Pool) enqueue(ctx context.Context, q queuedJob) error {
+	queue := p.queues[q.priority.level()]
+	tags := p.tags(q.priority)
+
+	if ctx == nil {
+		select {
+		case queue <- q:
+			p.sink().Gauge("pool.queue_depth", float64(len(queue)), tags...)
+			return nil
+		default:
+			p.sink().Count("pool.rejected", 1, tags...)
+			return ErrQueueFull
+		}
+	}
+
+	select {
+	case queue <- q:
+		p.sink().Gauge("pool.queue_depth", float64(len(queue)), tags...)
+		return nil
+	case <-p.closing:
+		return ErrPoolClosed
+	case <-ctx.Done():
+		p.sink().Count("pool.rejected", 1, tags...)
+		return ctx.Err()
+	}
+}
+
+// QueueDepth returns the number of jobs waiting for a worker.
+func (p *Pool) QueueDepth() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	depth := 0
+	for _, q := range p.queues {
+		depth += len(q)
+	}
+
+	return depth
+}
+
+// sink returns the metrics sink of the pool.
+func (p *Pool) sink() MetricsSink {
+	if p.opts.Metrics != nil {
+		return p.opts.Metrics
+	}
+
+	if c := p.conductor.Load(); c != nil {
+		return c.sink()
+	}
+
+	return nopSink{}
+}
+
+func (p *Pool) tags(priority Priority) []Tag {
+	return []Tag{{Key: "pool", Value: p.name}, {Key: "priority", Value: priority.String()}}
+}
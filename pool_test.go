@@ -0,0 +1,271 @@
+package parallel_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/franklad/parallel"
+)
+
+// runPool runs p until the returned function stops it with a stop context
+// that expires after timeout, returning the error of Stop. Callers only stop
+// the pool once a job has started, so Stop does not overtake Run.
+func runPool(t *testing.T, p *parallel.Pool) func(timeout time.Duration) error {
+	t.Helper()
+
+	ran := make(chan error, 1)
+	go func() { ran <- p.Run(context.Background()) }()
+
+	return func(timeout time.Duration) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		err := p.Stop(ctx)
+		select {
+		case <-ran:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Run did not return after Stop")
+		}
+
+		return err
+	}
+}
+
+func TestPoolStarvationLimit(t *testing.T) {
+	p := parallel.NewPool("pool", parallel.PoolOptions{Workers: 1, QueueSize: 16, StarvationLimit: 3})
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+
+	record := func(name string) parallel.Job {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	if _, err := p.TrySubmit(record("low"), parallel.AtPriority(parallel.PriorityLow)); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 10 {
+		if _, err := p.TrySubmit(record(fmt.Sprint("high", i)), parallel.AtPriority(parallel.PriorityHigh)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stop := runPool(t, p)
+	waitFor(t, "the jobs to run", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(order) == 11
+	})
+
+	if err := stop(2 * time.Second); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if i := slices.Index(order, "low"); i != 3 {
+		t.Errorf("low job ran at position %d, want 3 after the starvation limit: %v", i, order)
+	}
+}
+
+func TestPoolStopDrainsQueuedJobs(t *testing.T) {
+	p := parallel.NewPool("pool", parallel.PoolOptions{Workers: 1, QueueSize: 8})
+	stop := runPool(t, p)
+
+	started := make(chan struct{})
+	var once sync.Once
+
+	var ids []parallel.JobID
+	for range 5 {
+		id, err := p.TrySubmit(func(context.Context) error {
+			once.Do(func() { close(started) })
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	<-started
+	if err := stop(2 * time.Second); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	for _, id := range ids {
+		s, err := p.Status(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s.State != parallel.JobSucceeded {
+			t.Errorf("job %d is %s after Stop, want %s", id, s.State, parallel.JobSucceeded)
+		}
+	}
+
+	if _, err := p.TrySubmit(func(context.Context) error { return nil }); !errors.Is(err, parallel.ErrPoolClosed) {
+		t.Errorf("TrySubmit after Stop = %v, want %v", err, parallel.ErrPoolClosed)
+	}
+}
+
+func TestPoolStopCancelsRunningJobs(t *testing.T) {
+	letters := make(chan parallel.DeadLetter, 1)
+	p := parallel.NewPool("pool", parallel.PoolOptions{Workers: 1, DeadLetters: parallel.DeadLetterChan(letters)})
+	stop := runPool(t, p)
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	id, err := p.SubmitTimeout(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}, 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-started
+	if err := stop(50 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	select {
+	case <-cancelled:
+	default:
+		t.Error("running job was not cancelled when the stop context expired")
+	}
+
+	s, err := p.Status(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.State != parallel.JobCancelled {
+		t.Errorf("job cancelled by Stop is %s, want %s", s.State, parallel.JobCancelled)
+	}
+
+	select {
+	case d := <-letters:
+		t.Errorf("job cancelled by Stop was dead-lettered: %v", d.Err)
+	default:
+	}
+}
+
+func TestPoolDeadLetterJoinsAttempts(t *testing.T) {
+	letters := make(chan parallel.DeadLetter, 1)
+	p := parallel.NewPool("pool", parallel.PoolOptions{
+		Workers:      1,
+		Retries:      2,
+		RetryBackoff: time.Millisecond,
+		DeadLetters:  parallel.DeadLetterChan(letters),
+	})
+	stop := runPool(t, p)
+	defer stop(2 * time.Second)
+
+	attemptErrs := []error{errors.New("first"), errors.New("second"), errors.New("third")}
+
+	var attempts int
+	id, err := p.SubmitTimeout(func(context.Context) error {
+		attempts++
+		return attemptErrs[attempts-1]
+	}, 2*time.Second, parallel.Payload("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var d parallel.DeadLetter
+	select {
+	case d = <-letters:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no dead letter")
+	}
+
+	if d.Job != id || d.Pool != "pool" || d.Payload != "payload" || d.Attempts != 3 {
+		t.Errorf("dead letter = %+v, want job %d of pool after 3 attempts with its payload", d, id)
+	}
+
+	for _, want := range attemptErrs {
+		if !errors.Is(d.Err, want) {
+			t.Errorf("dead letter error %q does not contain %q", d.Err, want)
+		}
+	}
+
+	s, err := p.Status(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.State != parallel.JobFailed || s.Attempts != 3 {
+		t.Errorf("status = %s after %d attempts, want %s after 3", s.State, s.Attempts, parallel.JobFailed)
+	}
+}
+
+func TestPoolPrunesFinishedJobs(t *testing.T) {
+	const keep, extra = 256, 10
+
+	p := parallel.NewPool("pool", parallel.PoolOptions{Workers: 4, QueueSize: keep + extra})
+
+	var (
+		ids []parallel.JobID
+		ran atomic.Int32
+	)
+
+	for range keep + extra {
+		id, err := p.TrySubmit(func(context.Context) error {
+			ran.Add(1)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if got := len(p.Jobs()); got != keep+extra {
+		t.Errorf("%d queued jobs tracked, want %d", got, keep+extra)
+	}
+
+	stop := runPool(t, p)
+	waitFor(t, "the jobs to run", func() bool { return ran.Load() == keep+extra })
+
+	if err := stop(2 * time.Second); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	jobs := p.Jobs()
+	if len(jobs) != keep {
+		t.Fatalf("%d finished jobs tracked, want %d", len(jobs), keep)
+	}
+
+	pruned := 0
+	for _, id := range ids {
+		if _, err := p.Status(id); errors.Is(err, parallel.ErrUnknownJob) {
+			pruned++
+		}
+	}
+
+	if pruned != extra {
+		t.Errorf("%d job statuses pruned, want %d", pruned, extra)
+	}
+}
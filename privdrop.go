@@ -0,0 +1,22 @@
+package parallel
+
+// WithPrivilegeDrop switches the process to the named user and group when
+// the conductor runs, after any option registered before it and before any
+// process starts. Open privileged resources, such as listeners on ports
+// below 1024, before calling Run and hand them to your processes. An empty
+// group uses the user's primary group. If the switch fails, Run is refused
+// with the error. Cleanups such as removing a PID file run unprivileged at
+// shutdown. It is only supported on Unix.
+func WithPrivilegeDrop(user, group string) Option {
+	return func(c *Conductor) {
+		c.onRun(func() (func(), error) {
+			uid, gid, err := dropPrivileges(user, group)
+			if err != nil {
+				return nil, err
+			}
+
+			c.log.Info("dropped privileges", "user", user, "uid", uid, "gid", gid)
+			return nil, nil
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package parallel
+
+import "errors"
+
+func dropPrivileges(string, string) (int, int, error) {
+	return 0, 0, errors.New("privilege drop is not supported on this platform")
+}
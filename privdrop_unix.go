@@ -0,0 +1,57 @@
+//go:build unix
+
+package parallel
+
+import (
+	"fmt"
+	"os"
+	osuser "os/user"
+	"strconv"
+	"syscall"
+)
+
+func dropPrivileges(username, groupname string) (int, int, error) {
+	u, err := osuser.Lookup(username)
+	if err != nil {
+		return 0, 0, fmt.Errorf("privilege drop: %w", err)
+	}
+
+	gidStr := u.Gid
+	if groupname != "" {
+		g, err := osuser.LookupGroup(groupname)
+		if err != nil {
+			return 0, 0, fmt.Errorf("privilege drop: %w", err)
+		}
+
+		gidStr = g.Gid
+	}
+
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return 0, 0, fmt.Errorf("privilege drop: invalid uid %q", u.Uid)
+	}
+
+	gid, err := strconv.Atoi(gidStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("privilege drop: invalid gid %q", gidStr)
+	}
+
+	if os.Getuid() == uid && os.Geteuid() == uid && os.Getgid() == gid && os.Getegid() == gid {
+		return uid, gid, nil
+	}
+
+	// The group has to change first, while the process may still do so.
+	if err := syscall.Setgroups([]int{gid}); err != nil {
+		return 0, 0, fmt.Errorf("privilege drop: setgroups: %w", err)
+	}
+
+	if err := syscall.Setgid(gid); err != nil {
+		return 0, 0, fmt.Errorf("privilege drop: setgid: %w", err)
+	}
+
+	if err := syscall.Setuid(uid); err != nil {
+		return 0, 0, fmt.Errorf("privilege drop: setuid: %w", err)
+	}
+
+	return uid, gid, nil
+}
@@ -0,0 +1,81 @@
+package parallel
+
+import (
+	"os"
+	"slices"
+)
+
+// ProfilesEnv names the environment variable holding the comma-separated
+// active profiles when WithProfiles is not used.
+const ProfilesEnv = "PARALLEL_PROFILES"
+
+// ProfileAll activates every profile.
+const ProfileAll = "all"
+
+// Profiled is implemented by processes that only run when one of their
+// profiles is active.
+type Profiled interface {
+	Profiles() []string
+}
+
+type profiledProcess struct {
+	Process
+	profiles []string
+}
+
+// InProfile makes p run only when one of profiles is active. Processes
+// without profiles always run.
+func InProfile(p Process, profiles ...string) Process {
+	return &profiledProcess{
+		Process:  p,
+		profiles: profiles,
+	}
+}
+
+func (p *profiledProcess) Profiles() []string {
+	return p.profiles
+}
+
+func (p *profiledProcess) Unwrap() Process {
+	return p.Process
+}
+
+// WithProfiles sets the active profiles, taking precedence over
+// PARALLEL_PROFILES. Activating ProfileAll runs every process.
+func WithProfiles(profiles ...string) Option {
+	return func(c *Conductor) {
+		c.profiles = profiles
+	}
+}
+
+// activeProfiles returns the profiles of WithProfiles or, failing that,
+// PARALLEL_PROFILES. The caller must hold c.mu.
+func (c *Conductor) activeProfiles() []string {
+	if c.profiles != nil {
+		return c.profiles
+	}
+
+	return splitList(os.Getenv(ProfilesEnv))
+}
+
+// profileActive reports whether p has no profiles or one of them is
+// active. The caller must hold c.mu.
+func (c *Conductor) profileActive(p Process) bool {
+	profiled, ok := as[Profiled](p)
+	if !ok || len(profiled.Profiles()) == 0 {
+		return true
+	}
+
+	active := c.activeProfiles()
+	if slices.Contains(active, ProfileAll) {
+		return true
+	}
+
+	for _, profile := range profiled.Profiles() {
+		if slices.Contains(active, profile) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,113 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Progresser is implemented by long-running batch processes that can
+// report how much of their work is done, so they show percentage-complete
+// instead of appearing hung. A total of zero means it is not known yet.
+type Progresser interface {
+	Progress() (done, total int64)
+}
+
+// Progress is the progress of a process or pool job.
+type Progress struct {
+	Done  int64 `json:"done"`
+	Total int64 `json:"total"`
+}
+
+// Percent returns how much of the total is done, or -1 when the total is
+// not known.
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+
+	return 100 * float64(p.Done) / float64(p.Total)
+}
+
+type progressKey struct{}
+
+// progressTracker holds the progress reported with ReportProgress.
+type progressTracker struct {
+	done, total atomic.Int64
+}
+
+func (t *progressTracker) Progress() (done, total int64) {
+	if t == nil {
+		return 0, 0
+	}
+
+	return t.done.Load(), t.total.Load()
+}
+
+// ReportProgress records the progress of the Task or pool job running with
+// ctx. It does nothing for other contexts.
+func ReportProgress(ctx context.Context, done, total int64) {
+	if t, ok := ctx.Value(progressKey{}).(*progressTracker); ok {
+		t.done.Store(done)
+		t.total.Store(total)
+	}
+}
+
+// withProgress returns ctx carrying t for ReportProgress.
+func withProgress(ctx context.Context, t *progressTracker) context.Context {
+	return context.WithValue(ctx, progressKey{}, t)
+}
+
+// Progress returns the progress of the process, or false if it does not
+// implement Progresser or has not reported any progress yet.
+func (h *Handle) Progress() (Progress, bool) {
+	p, ok := as[Progresser](h.entry.process)
+	if !ok {
+		return Progress{}, false
+	}
+
+	done, total := p.Progress()
+	return Progress{Done: done, Total: total}, done != 0 || total != 0
+}
+
+// WithProgressEvents registers a process that checks the progress of every
+// running Progresser each interval and emits EventProcessProgress when it
+// has changed.
+func WithProgressEvents(interval time.Duration) Option {
+	return func(c *Conductor) {
+		r := &progressReporter{conductor: c}
+
+		c.register(&periodic{
+			name:     "progress-events",
+			interval: interval,
+			tick:     r.check,
+		})
+	}
+}
+
+type progressReporter struct {
+	conductor *Conductor
+
+	mu   sync.Mutex
+	last map[string]Progress
+}
+
+func (r *progressReporter) check(context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.last == nil {
+		r.last = make(map[string]Progress)
+	}
+
+	for _, h := range r.conductor.Processes() {
+		p, ok := h.Progress()
+		if !ok || h.State() != ProcessRunning || r.last[h.Name()] == p {
+			continue
+		}
+
+		r.last[h.Name()] = p
+		r.conductor.emit(Event{Type: EventProcessProgress, Process: h.Name(), Progress: &p})
+	}
+}
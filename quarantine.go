@@ -0,0 +1,182 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrQuarantined = errors.New("process is quarantined")
+
+// Quarantine takes a repeatedly failing process out of service instead of
+// shutting down the conductor. A process that fails fewer than Failures
+// times within Window is restarted; on reaching Failures it is stopped,
+// marked failed and no longer restarted. A zero Window counts every failure
+// of the run. When Probe is positive, the conductor tries to rejoin a
+// quarantined process after that long. A fatal failure quarantines the
+// process straight away, without a probe.
+type Quarantine struct {
+	Failures int
+	Window   time.Duration
+	Probe    time.Duration
+}
+
+func WithQuarantine(q Quarantine) Option {
+	return func(c *Conductor) {
+		c.quarantine = &q
+	}
+}
+
+// quarantineFailure applies the quarantine policy to err and reports
+// whether it did, in which case the conductor keeps running.
+func (c *Conductor) quarantineFailure(err *Error) bool {
+	c.mu.Lock()
+	q, e := c.quarantine, c.find(err.Process)
+	c.mu.Unlock()
+
+	if q == nil || e == nil {
+		return false
+	}
+
+	now := time.Now()
+
+	e.mu.Lock()
+	e.failures = append(e.failures, now)
+	if q.Window > 0 {
+		for len(e.failures) > 0 && now.Sub(e.failures[0]) > q.Window {
+			e.failures = e.failures[1:]
+		}
+	}
+
+	failures := len(e.failures)
+	e.mu.Unlock()
+
+	if failures < q.Failures && !err.Fatal() {
+		if !err.suppressed {
+			c.log.Warn("restarting failed process", "process", e.name(), "failures", failures)
+		}
+
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+
+			if err := c.restart(ctx, e); err != nil && !errors.Is(err, ErrNotRunning) {
+				c.log.Error("failed to restart process", "process", e.name(), "error", err)
+			}
+		}()
+
+		return true
+	}
+
+	probe := q.Probe
+	if err.Fatal() {
+		probe = 0
+	}
+
+	c.quarantineEntry(e, probe)
+	return true
+}
+
+// quarantineEntry marks e as quarantined and, when probe is positive,
+// schedules an attempt to rejoin it.
+func (c *Conductor) quarantineEntry(e *entry, probe time.Duration) {
+	c.markQuarantined(e, probe)
+
+	c.log.Warn("quarantined process", "process", e.name(), "probe", probe)
+	c.emit(Event{Type: EventProcessQuarantined, Process: e.name()})
+}
+
+// markQuarantined is quarantineEntry without the log entry and event.
+func (c *Conductor) markQuarantined(e *entry, probe time.Duration) {
+	e.mu.Lock()
+	e.quarantined = true
+	e.state = ProcessFailed
+	if e.probe != nil {
+		e.probe.Stop()
+		e.probe = nil
+	}
+
+	if probe > 0 {
+		e.probe = time.AfterFunc(probe, func() {
+			c.log.Info("probing quarantined process", "process", e.name())
+
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+
+			if err := c.release(ctx, e); err != nil && !errors.Is(err, ErrNotRunning) {
+				c.log.Error("failed to rejoin quarantined process", "process", e.name(), "error", err)
+			}
+		})
+	}
+	e.mu.Unlock()
+}
+
+// release takes e out of quarantine and starts it again.
+func (c *Conductor) release(ctx context.Context, e *entry) error {
+	e.mu.Lock()
+	if !e.quarantined {
+		e.mu.Unlock()
+		return nil
+	}
+
+	e.quarantined = false
+	if e.probe != nil {
+		e.probe.Stop()
+		e.probe = nil
+	}
+	e.mu.Unlock()
+
+	return c.restart(ctx, e)
+}
+
+// clearQuarantine resets the quarantine state of e for a new run.
+func (e *entry) clearQuarantine() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.quarantined = false
+	e.failures = nil
+	if e.probe != nil {
+		e.probe.Stop()
+		e.probe = nil
+	}
+}
+
+func (e *entry) isQuarantined() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.quarantined
+}
+
+// Quarantine stops the process and keeps it out of service until Release
+// is called or, with a Quarantine policy that sets Probe, until it is
+// probed.
+func (h *Handle) Quarantine(ctx context.Context) (err error) {
+	defer func() { h.conductor.audit(ctx, "quarantine", h.Name(), err) }()
+
+	if err := h.conductor.stopProcess(ctx, h.entry); err != nil && !errors.Is(err, ErrNotRunning) {
+		return err
+	}
+
+	var probe time.Duration
+	h.conductor.mu.Lock()
+	if q := h.conductor.quarantine; q != nil {
+		probe = q.Probe
+	}
+	h.conductor.mu.Unlock()
+
+	h.conductor.quarantineEntry(h.entry, probe)
+	return nil
+}
+
+// Release takes the process out of quarantine and starts it again.
+func (h *Handle) Release(ctx context.Context) error {
+	err := h.conductor.release(ctx, h.entry)
+	h.conductor.audit(ctx, "release", h.Name(), err)
+	return err
+}
+
+func (h *Handle) Quarantined() bool {
+	return h.entry.isQuarantined()
+}
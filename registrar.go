@@ -0,0 +1,135 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Registrar registers the service with a discovery system such as Consul
+// or etcd. Deregister should tolerate a service that is not registered.
+type Registrar interface {
+	Register(ctx context.Context) error
+	Deregister(ctx context.Context) error
+}
+
+type registration struct {
+	registrar Registrar
+
+	mu         sync.Mutex
+	pending    chan struct{}
+	cancel     context.CancelFunc
+	registered bool
+}
+
+// WithRegistrar registers the service with r once startup completes and
+// every process is ready, and deregisters it as soon as shutdown begins,
+// before any process is stopped, so the instance never receives traffic
+// before it is ready or after it starts draining. A failed registration is
+// logged and the conductor keeps running unregistered.
+func WithRegistrar(r Registrar) Option {
+	return func(c *Conductor) {
+		reg := &registration{registrar: r}
+
+		c.registrations = append(c.registrations, reg)
+		c.integrations = append(c.integrations, "registrar")
+		c.listeners = append(c.listeners, func(e Event) {
+			if e.Type == EventStartupComplete {
+				c.registerService(reg)
+			}
+		})
+	}
+}
+
+// registerService starts registering reg unless it is already registered in the
+// current run.
+func (c *Conductor) registerService(reg *registration) {
+	c.mu.Lock()
+	if c.state != stateRunning {
+		c.mu.Unlock()
+		return
+	}
+
+	ctx := c.ctx
+	c.mu.Unlock()
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if reg.pending != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	pending := make(chan struct{})
+	reg.pending, reg.cancel, reg.registered = pending, cancel, false
+
+	go func() {
+		defer close(pending)
+
+		err := reg.registrar.Register(ctx)
+
+		// A registration interrupted by shutdown may still have reached
+		// the registry, so it is deregistered all the same.
+		reg.mu.Lock()
+		reg.registered = err == nil || ctx.Err() != nil
+		reg.mu.Unlock()
+
+		if err != nil && ctx.Err() == nil {
+			c.log.Error("failed to register service", "error", err)
+			return
+		}
+
+		if err == nil {
+			c.log.Info("registered service")
+			c.emit(Event{Type: EventServiceRegistered})
+		}
+	}()
+}
+
+// deregisterServices deregisters every registration of the current run,
+// cancelling registrations still in progress, within timeout.
+func (c *Conductor) deregisterServices(timeout time.Duration) {
+	c.mu.Lock()
+	registrations := c.registrations
+	c.mu.Unlock()
+
+	if len(registrations) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, reg := range registrations {
+		reg.mu.Lock()
+		pending, stop := reg.pending, reg.cancel
+		reg.pending, reg.cancel = nil, nil
+		reg.mu.Unlock()
+
+		if pending == nil {
+			continue
+		}
+
+		stop()
+		<-pending
+
+		reg.mu.Lock()
+		registered := reg.registered
+		reg.registered = false
+		reg.mu.Unlock()
+
+		if !registered {
+			continue
+		}
+
+		started := time.Now()
+		if err := reg.registrar.Deregister(ctx); err != nil {
+			c.log.Error("failed to deregister service", "error", err)
+			continue
+		}
+
+		c.log.Info("deregistered service", "duration", time.Since(started))
+		c.emit(Event{Type: EventServiceDeregistered, Duration: time.Since(started)})
+	}
+}
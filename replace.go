@@ -0,0 +1,259 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// candidate is a process started alongside the one it is meant to replace.
+// Its failures are kept apart from the run's error channel until it is
+// promoted, so that a broken replacement does not take the conductor down.
+type candidate struct {
+	old       *entry
+	entry     *entry
+	done      <-chan struct{}
+	errs      *errorStream
+	runErrs   *errorStream
+	abandoned sync.Once
+}
+
+// Replace upgrades the process called name in place, blue/green style. It
+// starts next alongside the current process and waits for it to be ready,
+// then swaps it in and drains and stops the old one. next must have the
+// same name. If next fails or exits before becoming ready, it is stopped
+// and the current process keeps running untouched. Handles looked up
+// before the swap keep referring to the old process.
+func (c *Conductor) Replace(ctx context.Context, name string, next Process) (err error) {
+	defer func() { c.audit(ctx, "replace", name, err) }()
+
+	cand, err := c.startCandidate(ctx, name, next)
+	if err != nil {
+		return err
+	}
+
+	return c.promote(ctx, cand)
+}
+
+// startCandidate starts next as a candidate to replace the process called
+// name and waits until it is ready.
+func (c *Conductor) startCandidate(ctx context.Context, name string, next Process) (*candidate, error) {
+	if next.Name() != name {
+		return nil, fmt.Errorf("replacement for %s is named %s", name, next.Name())
+	}
+
+	old, err := c.control(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !old.running() {
+		return nil, ErrNotRunning
+	}
+
+	c.mu.Lock()
+	if c.state != stateRunning {
+		c.mu.Unlock()
+		return nil, ErrNotRunning
+	}
+
+	e := newEntry(c.wrap(next))
+	e.builtin = old.builtin
+	e.resetReady()
+	cand := &candidate{old: old, entry: e, errs: newErrorStream(1), runErrs: c.errors}
+
+	c.log.Info("starting replacement process", "process", name)
+	c.startWith(c.ctx, e, nil, cand.errs)
+
+	e.mu.Lock()
+	ready := e.ready
+	cand.done = e.done
+	e.mu.Unlock()
+
+	if c.candidates == nil {
+		c.candidates = make(map[*candidate]struct{})
+	}
+
+	c.candidates[cand] = struct{}{}
+	c.mu.Unlock()
+
+	select {
+	case <-ready:
+		return cand, nil
+	case perr := <-cand.errs.ch:
+		c.abandon(cand)
+		return nil, perr
+	case <-cand.done:
+		c.abandon(cand)
+		return nil, fmt.Errorf("replacement for %s exited before becoming ready", name)
+	case <-ctx.Done():
+		c.abandon(cand)
+		return nil, ctx.Err()
+	}
+}
+
+// promote swaps cand in for the process it replaces and stops the old one.
+func (c *Conductor) promote(ctx context.Context, cand *candidate) error {
+	name := cand.entry.name()
+
+	c.mu.Lock()
+	i := slices.Index(c.entries, cand.old)
+	if c.state != stateRunning || i < 0 {
+		c.mu.Unlock()
+		c.abandon(cand)
+		return ErrNotRunning
+	}
+
+	_, restarts := cand.old.status()
+	cand.entry.mu.Lock()
+	cand.entry.restarts = restarts
+	cand.entry.mu.Unlock()
+
+	c.entries[i] = cand.entry
+	delete(c.candidates, cand)
+	c.mu.Unlock()
+
+	go func() {
+		select {
+		case perr := <-cand.errs.ch:
+			cand.runErrs.send(perr)
+		case <-cand.done:
+			select {
+			case perr := <-cand.errs.ch:
+				cand.runErrs.send(perr)
+			default:
+			}
+		}
+	}()
+
+	if err := c.stopProcess(ctx, cand.old); err != nil {
+		return fmt.Errorf("stopping replaced process %s: %w", name, err)
+	}
+
+	c.log.Info("replaced process", "process", name)
+	return nil
+}
+
+// abandon stops a candidate that is not going to be promoted and waits for
+// its Run to return. Only the first call for a candidate does anything.
+func (c *Conductor) abandon(cand *candidate) {
+	cand.abandoned.Do(func() {
+		c.mu.Lock()
+		delete(c.candidates, cand)
+		c.mu.Unlock()
+
+		e := cand.entry
+		e.mu.Lock()
+		e.interrupt = interruptStop
+		e.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if !isClosed(cand.done) {
+			if err := c.interrupt(ctx, e, cand.done); err != nil {
+				c.log.Error("failed to stop abandoned replacement", "process", e.name(), "error", err)
+			}
+		}
+
+		cand.errs.close()
+		c.log.Warn("abandoned replacement process", "process", e.name())
+	})
+}
+
+// abandonAll abandons the candidates not yet promoted when the shutdown
+// begins, so that none outlives it.
+func (c *Conductor) abandonAll(candidates map[*candidate]struct{}) {
+	var wg sync.WaitGroup
+	for cand := range candidates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.abandon(cand)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// CanaryOptions configures Canary.
+type CanaryOptions struct {
+	// Duration is how long the canary must stay healthy to be promoted.
+	Duration time.Duration
+	// CheckInterval is how often the canary's health is checked, which
+	// defaults to a tenth of Duration.
+	CheckInterval time.Duration
+}
+
+// Canary runs next as a canary alongside the process called name. Once the
+// canary is ready, it must stay healthy for opts.Duration: if it does, it
+// is promoted and replaces the old process as with Replace, and if it fails,
+// exits or reports itself unhealthy, it is stopped and the old process
+// keeps running. EventCanaryStarted, EventCanaryPromoted and
+// EventCanaryRolledBack are emitted for each step.
+func (c *Conductor) Canary(ctx context.Context, name string, next Process, opts CanaryOptions) (err error) {
+	defer func() { c.audit(ctx, "canary", name, err) }()
+
+	interval := opts.CheckInterval
+	if interval <= 0 {
+		interval = max(opts.Duration/10, time.Millisecond)
+	}
+
+	began := time.Now()
+	cand, err := c.startCandidate(ctx, name, next)
+	if err != nil {
+		c.emit(Event{Type: EventCanaryRolledBack, Process: name, Duration: time.Since(began), Err: err})
+		return err
+	}
+
+	c.log.Info("canary started", "process", name, "duration", opts.Duration)
+	c.emit(Event{Type: EventCanaryStarted, Process: name})
+
+	err = c.observe(ctx, cand, opts.Duration, interval)
+	if err == nil {
+		err = c.promote(ctx, cand)
+	} else {
+		c.abandon(cand)
+	}
+
+	if err != nil {
+		c.log.Warn("canary rolled back", "process", name, "error", err)
+		c.emit(Event{Type: EventCanaryRolledBack, Process: name, Duration: time.Since(began), Err: err})
+		return fmt.Errorf("canary %s rolled back: %w", name, err)
+	}
+
+	c.log.Info("canary promoted", "process", name)
+	c.emit(Event{Type: EventCanaryPromoted, Process: name, Duration: time.Since(began)})
+	return nil
+}
+
+// observe waits for d, checking the health of cand every interval, and
+// returns the first sign of trouble.
+func (c *Conductor) observe(ctx context.Context, cand *candidate, d, interval time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return nil
+		case perr := <-cand.errs.ch:
+			return perr
+		case <-cand.done:
+			return fmt.Errorf("canary exited")
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if checker, ok := as[HealthChecker](cand.entry.process); ok {
+				if err := checker.Health(ctx); err != nil {
+					return fmt.Errorf("canary unhealthy: %w", err)
+				}
+			}
+		}
+	}
+}
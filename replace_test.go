@@ -0,0 +1,51 @@
+package parallel_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/franklad/parallel"
+	"github.com/franklad/parallel/conductortest"
+)
+
+func TestShutdownAbandonsPendingCanary(t *testing.T) {
+	var canaryReturned atomic.Bool
+	old := blocking("api", nil)
+	canary := parallel.Task("api", func(ctx context.Context) error {
+		<-ctx.Done()
+		canaryReturned.Store(true)
+		return nil
+	})
+
+	c := conductortest.New(old).With(parallel.WithLogger(discard()))
+	c.Run(context.Background())
+
+	result := make(chan error, 1)
+	go func() {
+		result <- c.Canary(context.Background(), "api", canary, parallel.CanaryOptions{Duration: time.Minute})
+	}()
+
+	// Give the canary time to start before shutting down.
+	time.Sleep(100 * time.Millisecond)
+	c.Shutdown("test")
+
+	if err := c.ThenStop(); err != nil {
+		t.Fatalf("ThenStop: %v", err)
+	}
+
+	if !canaryReturned.Load() {
+		t.Error("canary still running after ThenStop")
+	}
+
+	select {
+	case err := <-result:
+		if err == nil || errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Canary = %v, want a rollback", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Canary did not return after the shutdown")
+	}
+}
@@ -0,0 +1,72 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+type PanicError struct {
+	Process string
+	Value   any
+	Stack   []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("process %q panicked: %v", e.Process, e.Value)
+}
+
+// runRecovered calls p.Run, converting a panic into a *PanicError.
+func runRecovered(ctx context.Context, p Process) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{
+				Process: p.Name(),
+				Value:   v,
+				Stack:   debug.Stack(),
+			}
+		}
+	}()
+
+	return p.Run(ctx)
+}
+
+type Report struct {
+	Process string
+	Err     error
+	Panic   any
+	Stack   []byte
+	Time    time.Time
+}
+
+// Reporter receives process errors and recovered panics, for forwarding to
+// services such as Sentry, Bugsnag, or Rollbar.
+type Reporter interface {
+	Report(ctx context.Context, r Report)
+}
+
+func WithErrorReporter(r Reporter) Option {
+	return func(c *Conductor) {
+		c.reporters = append(c.reporters, r)
+	}
+}
+
+func (c *Conductor) report(ctx context.Context, err *Error) {
+	r := Report{
+		Process: err.Process,
+		Err:     err,
+		Stack:   err.Stack,
+		Time:    time.Now(),
+	}
+
+	var p *PanicError
+	if errors.As(err, &p) {
+		r.Panic = p.Value
+	}
+
+	for _, reporter := range c.reporters {
+		reporter.Report(ctx, r)
+	}
+}
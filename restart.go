@@ -0,0 +1,148 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Restart gracefully stops the process called name and runs it again,
+// returning once it is ready again. The interruption is not treated as a
+// failure. It fails if the restarted process exits before becoming ready.
+func (c *Conductor) Restart(ctx context.Context, name string) (err error) {
+	defer func() { c.audit(ctx, "restart", name, err) }()
+
+	e, err := c.control(name)
+	if err != nil {
+		return err
+	}
+
+	if err := c.restart(ctx, e); err != nil {
+		return err
+	}
+
+	return c.awaitRestart(ctx, e)
+}
+
+// control looks up the process called name for a control action.
+func (c *Conductor) control(name string) (*entry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.find(name)
+	switch {
+	case e == nil:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProcess, name)
+	case c.skipped[e] != "":
+		return nil, fmt.Errorf("%w: %s", ErrProcessSkipped, name)
+	}
+
+	return e, nil
+}
+
+// awaitRestart waits until the freshly restarted e is ready.
+func (c *Conductor) awaitRestart(ctx context.Context, e *entry) error {
+	e.mu.Lock()
+	ready, done := e.ready, e.done
+	e.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-done:
+		state, _ := e.status()
+		return fmt.Errorf("process %s is %s before becoming ready", e.name(), state)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RollingRestartOptions configures RollingRestart.
+type RollingRestartOptions struct {
+	// Concurrency is the number of processes restarted at a time, which
+	// defaults to 1.
+	Concurrency int
+	// Processes limits the restart to the named processes. By default every
+	// running process is restarted, except those registered by options.
+	Processes []string
+	// Pause is waited between batches.
+	Pause time.Duration
+}
+
+// RollingRestart restarts processes a batch at a time, waiting for every
+// process of a batch to be ready again before starting the next one, so that
+// configuration read at start can be refreshed without downtime. Processes
+// are restarted in dependency order. The first failure aborts the rollout,
+// leaving the remaining processes untouched.
+func (c *Conductor) RollingRestart(ctx context.Context, opts RollingRestartOptions) (err error) {
+	defer func() { c.audit(ctx, "rolling_restart", strings.Join(opts.Processes, ","), err) }()
+
+	c.mu.Lock()
+	if c.state != stateRunning {
+		c.mu.Unlock()
+		return ErrNotRunning
+	}
+
+	var names []string
+	for _, level := range c.stopLevels() {
+		for _, e := range level {
+			switch {
+			case opts.Processes != nil && !slices.Contains(opts.Processes, e.name()):
+			case opts.Processes == nil && (e.builtin || !e.running()):
+			default:
+				names = append(names, e.name())
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	for _, name := range opts.Processes {
+		if !slices.Contains(names, name) {
+			if _, err := c.control(name); err != nil {
+				return err
+			}
+		}
+	}
+
+	batch := max(opts.Concurrency, 1)
+	c.log.Info("starting rolling restart", "processes", names, "concurrency", batch)
+
+	for i := 0; i < len(names); i += batch {
+		if i > 0 && opts.Pause > 0 {
+			select {
+			case <-time.After(opts.Pause):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		group := names[i:min(i+batch, len(names))]
+		errs := make([]error, len(group))
+
+		var wg sync.WaitGroup
+		for j, name := range group {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				if err := c.Restart(ctx, name); err != nil {
+					errs[j] = fmt.Errorf("%s: %w", name, err)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		if err := errors.Join(errs...); err != nil {
+			c.log.Error("rolling restart aborted", "restarted", names[:i], "error", err)
+			return fmt.Errorf("rolling restart: %w", err)
+		}
+	}
+
+	c.log.Info("rolling restart complete", "processes", names)
+	return nil
+}
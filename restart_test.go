@@ -0,0 +1,126 @@
+package parallel_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/franklad/parallel"
+	"github.com/franklad/parallel/conductortest"
+)
+
+// slowExit returns from Run a while after Stop, and fails Stop while
+// stopErr is set.
+type slowExit struct {
+	runs    atomic.Int32
+	stopErr atomic.Pointer[error]
+	quit    chan chan struct{}
+}
+
+func newSlowExit() *slowExit {
+	return &slowExit{quit: make(chan chan struct{}, 1)}
+}
+
+func (p *slowExit) Name() string { return "slow" }
+
+func (p *slowExit) Run(ctx context.Context) error {
+	p.runs.Add(1)
+	quit := make(chan struct{})
+	p.quit <- quit
+
+	select {
+	case <-quit:
+		time.Sleep(200 * time.Millisecond)
+	case <-ctx.Done():
+	}
+
+	return nil
+}
+
+func (p *slowExit) Stop(ctx context.Context) error {
+	if err := p.stopErr.Load(); err != nil {
+		return *err
+	}
+
+	close(<-p.quit)
+	return nil
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRestartSurvivesCancelledContext(t *testing.T) {
+	p := newSlowExit()
+	c := conductortest.New(p).With(parallel.WithLogger(discard()))
+	c.Run(context.Background())
+	defer func() {
+		c.Shutdown("test")
+		c.ThenStop()
+	}()
+
+	h, _ := c.Lookup("slow")
+	waitFor(t, "the process to run", func() bool { return h.State() == parallel.ProcessRunning })
+
+	// The context ends after Stop returns but before Run does.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	h.Restart(ctx)
+
+	waitFor(t, "the process to run again", func() bool {
+		return p.runs.Load() == 2 && h.State() == parallel.ProcessRunning
+	})
+
+	if n := h.Restarts(); n != 1 {
+		t.Errorf("Restarts() = %d, want 1", n)
+	}
+}
+
+func TestRestartFailedStopRestoresState(t *testing.T) {
+	p := newSlowExit()
+	c := conductortest.New(p).With(parallel.WithLogger(discard()))
+	c.Run(context.Background())
+	defer func() {
+		p.stopErr.Store(nil)
+		c.Shutdown("test")
+		c.ThenStop()
+	}()
+
+	h, _ := c.Lookup("slow")
+	waitFor(t, "the process to run", func() bool { return h.State() == parallel.ProcessRunning })
+
+	stopErr := errors.New("stop failed")
+	p.stopErr.Store(&stopErr)
+
+	if err := h.Restart(context.Background()); !errors.Is(err, stopErr) {
+		t.Fatalf("Restart = %v, want %v", err, stopErr)
+	}
+
+	if state := h.State(); state != parallel.ProcessRunning {
+		t.Errorf("State() = %s, want running", state)
+	}
+
+	if n := h.Restarts(); n != 0 {
+		t.Errorf("Restarts() = %d, want 0", n)
+	}
+
+	// A later restart still works.
+	p.stopErr.Store(nil)
+	if err := h.Restart(context.Background()); err != nil {
+		t.Fatalf("Restart: %v", err)
+	}
+
+	waitFor(t, "the process to run again", func() bool { return p.runs.Load() == 2 })
+}
@@ -0,0 +1,152 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const selfCheckTimeout = 5 * time.Second
+
+// SelfCheck configures WithSelfCheck. Addresses are dialled over TCP in
+// addition to the address of every process implementing Addresser, and
+// URLs, such as the service's external endpoint, must answer a GET with a
+// status below 400. Timeout bounds each probe and defaults to 5 seconds.
+type SelfCheck struct {
+	Addresses []string
+	URLs      []string
+	Timeout   time.Duration
+}
+
+// WithSelfCheck registers a process called "self-check" that, once startup
+// completes, verifies the service is reachable on its advertised addresses.
+// A failed check fails the process, and with it the run, so port and
+// firewall misconfigurations surface at boot.
+func WithSelfCheck(check SelfCheck) Option {
+	return func(c *Conductor) {
+		if check.Timeout <= 0 {
+			check.Timeout = selfCheckTimeout
+		}
+
+		s := &selfCheck{
+			conductor: c,
+			check:     check,
+			complete:  make(chan struct{}),
+		}
+
+		c.listeners = append(c.listeners, s.observe)
+		c.register(Task("self-check", s.run))
+	}
+}
+
+type selfCheck struct {
+	conductor *Conductor
+	check     SelfCheck
+
+	mu       sync.Mutex
+	complete chan struct{}
+}
+
+// observe tracks whether startup of the current run has completed.
+func (s *selfCheck) observe(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch e.Type {
+	case EventStartupComplete:
+		close(s.complete)
+	case EventShutdownComplete:
+		s.complete = make(chan struct{})
+	}
+}
+
+func (s *selfCheck) run(ctx context.Context) error {
+	s.mu.Lock()
+	complete := s.complete
+	s.mu.Unlock()
+
+	select {
+	case <-complete:
+	case <-ctx.Done():
+		return nil
+	}
+
+	var errs []error
+	for _, addr := range s.addresses() {
+		if err := s.dial(ctx, addr); err != nil {
+			errs = append(errs, fmt.Errorf("dial %s: %w", addr, err))
+		}
+	}
+
+	for _, url := range s.check.URLs {
+		if err := s.get(ctx, url); err != nil {
+			errs = append(errs, fmt.Errorf("get %s: %w", url, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("service is not reachable: %w", err)
+	}
+
+	s.conductor.log.Info("self-check passed")
+	return nil
+}
+
+// addresses returns the configured addresses and those of the processes
+// taking part in the run.
+func (s *selfCheck) addresses() []string {
+	c := s.conductor
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	addrs := append([]string(nil), s.check.Addresses...)
+	for _, e := range c.entries {
+		if c.skipped[e] != "" || !e.running() {
+			continue
+		}
+
+		if a, ok := as[Addresser](e.process); ok && a.Addr() != "" {
+			addrs = append(addrs, a.Addr())
+		}
+	}
+
+	return addrs
+}
+
+func (s *selfCheck) dial(ctx context.Context, addr string) error {
+	d := net.Dialer{Timeout: s.check.Timeout}
+
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+func (s *selfCheck) get(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.check.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+
+	return nil
+}
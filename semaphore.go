@@ -0,0 +1,139 @@
+package parallel
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// WeightedSemaphore bounds the total weight of the holders at once, such as
+// the bytes buffered by concurrent uploads. Waiters are served in order, so
+// a heavy acquisition is not starved by a stream of light ones.
+type WeightedSemaphore struct {
+	size int64
+
+	mu      sync.Mutex
+	cur     int64
+	waiters list.List
+}
+
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// NewWeightedSemaphore returns a semaphore with a total weight of size.
+func NewWeightedSemaphore(size int64) *WeightedSemaphore {
+	return &WeightedSemaphore{size: size}
+}
+
+// Acquire waits until a weight of n is available and takes it, failing with
+// ctx's error once ctx is done. Acquiring more than the size of the
+// semaphore waits until ctx is done.
+func (s *WeightedSemaphore) Acquire(ctx context.Context, n int64) error {
+	s.mu.Lock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+
+	w := semaphoreWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		select {
+		case <-w.ready:
+			// Acquired just as ctx was done; hand the weight back.
+			s.cur -= n
+		default:
+			front := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			if !front {
+				return ctx.Err()
+			}
+		}
+
+		s.notify()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes a weight of n if it is available right away, and reports
+// whether it did.
+func (s *WeightedSemaphore) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size-s.cur < n || s.waiters.Len() > 0 {
+		return false
+	}
+
+	s.cur += n
+	return true
+}
+
+// Release returns a weight of n taken with Acquire or TryAcquire.
+func (s *WeightedSemaphore) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cur -= n
+	if s.cur < 0 {
+		panic("parallel: semaphore released more than held")
+	}
+
+	s.notify()
+}
+
+// notify wakes the waiters at the front that fit. The caller must hold s.mu.
+func (s *WeightedSemaphore) notify() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+
+		w := front.Value.(semaphoreWaiter)
+		if s.size-s.cur < w.n {
+			return
+		}
+
+		s.cur += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+// Semaphore bounds how many holders run at once.
+type Semaphore struct {
+	weighted *WeightedSemaphore
+}
+
+// NewSemaphore returns a semaphore admitting n holders at once.
+func NewSemaphore(n int) *Semaphore {
+	return &Semaphore{weighted: NewWeightedSemaphore(int64(n))}
+}
+
+// Acquire waits for a free slot and takes it, failing with ctx's error once
+// ctx is done.
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	return s.weighted.Acquire(ctx, 1)
+}
+
+// TryAcquire takes a slot if one is free, and reports whether it did.
+func (s *Semaphore) TryAcquire() bool {
+	return s.weighted.TryAcquire(1)
+}
+
+// Release frees a slot taken with Acquire or TryAcquire.
+func (s *Semaphore) Release() {
+	s.weighted.Release(1)
+}
@@ -0,0 +1,317 @@
+package parallel
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultServerAddr = "127.0.0.1:8081"
+
+// ServerOptions configures NewServer. Addr defaults to 127.0.0.1:8081.
+// Serving on an address other than loopback requires TLS, unless
+// AllowInsecure is set, and AllowedClients, when set, limits the clients
+// served to those networks.
+type ServerOptions struct {
+	Addr           string
+	TLS            *TLSFiles
+	AllowedClients []netip.Prefix
+	AllowInsecure  bool
+}
+
+// TLSFiles are the PEM files a Server reads its certificate from. With
+// ClientCAFile, clients may present a certificate signed by one of its
+// CAs, for ClientCertificates to authenticate. The files are read again on
+// Reload, which the conductor calls on SIGHUP, and whenever they change,
+// checked every ReloadInterval, 30s by default.
+type TLSFiles struct {
+	CertFile       string
+	KeyFile        string
+	ClientCAFile   string
+	ReloadInterval time.Duration
+}
+
+// Server is a Process serving an HTTP handler, such as AdminHandler or
+// HealthHandler, with optional TLS.
+type Server struct {
+	name    string
+	handler http.Handler
+	opts    ServerOptions
+
+	mu    sync.Mutex
+	srv   *http.Server
+	addr  net.Addr
+	ready chan struct{}
+
+	conductor atomic.Pointer[Conductor]
+	config    atomic.Pointer[tls.Config]
+	loaded    atomic.Pointer[[3]time.Time]
+}
+
+// NewServer returns a Server called name serving handler. It keeps its own
+// copy of opts, so the caller may share or reuse them.
+func NewServer(name string, handler http.Handler, opts ServerOptions) *Server {
+	if opts.Addr == "" {
+		opts.Addr = defaultServerAddr
+	}
+
+	if opts.TLS != nil {
+		files := *opts.TLS
+		if files.ReloadInterval <= 0 {
+			files.ReloadInterval = 30 * time.Second
+		}
+
+		opts.TLS = &files
+	}
+
+	opts.AllowedClients = slices.Clone(opts.AllowedClients)
+
+	return &Server{name: name, handler: handler, opts: opts, ready: make(chan struct{})}
+}
+
+func (s *Server) Name() string {
+	return s.name
+}
+
+// Addr returns the address the server listens on once it runs, and the
+// configured address before.
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.addr != nil {
+		return s.addr.String()
+	}
+
+	return s.opts.Addr
+}
+
+func (s *Server) Run(ctx context.Context) error {
+	if err := s.checkBinding(); err != nil {
+		return err
+	}
+
+	if s.opts.TLS != nil {
+		if err := s.loadTLS(); err != nil {
+			return err
+		}
+	}
+
+	ln, err := net.Listen("tcp", s.opts.Addr)
+	if err != nil {
+		return err
+	}
+
+	if s.opts.TLS != nil {
+		ln = tls.NewListener(ln, &tls.Config{
+			GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+				return s.config.Load(), nil
+			},
+		})
+	}
+
+	srv := &http.Server{
+		Handler:           s.restrict(s.handler),
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	s.conductor.Store(conductorFrom(ctx))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.mu.Lock()
+	s.srv, s.addr = srv, ln.Addr()
+	if isClosed(s.ready) {
+		s.ready = make(chan struct{})
+	}
+	close(s.ready)
+	s.mu.Unlock()
+
+	if s.opts.TLS != nil {
+		go s.watch(ctx)
+	}
+
+	// A server the conductor could not stop still closes with its context.
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// Ready waits until the server listens.
+func (s *Server) Ready(ctx context.Context) error {
+	s.mu.Lock()
+	ready := s.ready
+	s.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop shuts the server down gracefully, waiting for the requests in
+// flight until ctx is done.
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	srv := s.srv
+	s.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+
+	return srv.Shutdown(ctx)
+}
+
+// Reload reads the TLS files again. A failed reload keeps the previous
+// certificate.
+func (s *Server) Reload(ctx context.Context) error {
+	if s.opts.TLS == nil {
+		return nil
+	}
+
+	return s.loadTLS()
+}
+
+// checkBinding refuses to serve beyond loopback without TLS.
+func (s *Server) checkBinding() error {
+	if s.opts.TLS != nil || s.opts.AllowInsecure {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(s.opts.Addr)
+	if err != nil {
+		return err
+	}
+
+	if ip, err := netip.ParseAddr(host); host == "localhost" || (err == nil && ip.IsLoopback()) {
+		return nil
+	}
+
+	return fmt.Errorf("refusing to serve %s on %s without TLS; listen on a loopback address, configure TLS or set AllowInsecure", s.name, s.opts.Addr)
+}
+
+// restrict rejects clients outside AllowedClients with 403.
+func (s *Server) restrict(next http.Handler) http.Handler {
+	if len(s.opts.AllowedClients) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addr, err := netip.ParseAddrPort(r.RemoteAddr)
+		if err == nil {
+			ip := addr.Addr().Unmap()
+			for _, p := range s.opts.AllowedClients {
+				if p.Contains(ip) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+
+		http.Error(w, "forbidden", http.StatusForbidden)
+	})
+}
+
+// loadTLS reads the TLS files into the configuration served to new
+// connections.
+func (s *Server) loadTLS() error {
+	t := s.opts.TLS
+	mod := s.modTimes()
+
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if t.ClientCAFile != "" {
+		pem, err := os.ReadFile(t.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("load client CAs: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("load client CAs: no certificates in %s", t.ClientCAFile)
+		}
+
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	s.config.Store(config)
+	s.loaded.Store(&mod)
+	return nil
+}
+
+// modTimes returns the modification times of the TLS files.
+func (s *Server) modTimes() [3]time.Time {
+	var mod [3]time.Time
+	for i, file := range []string{s.opts.TLS.CertFile, s.opts.TLS.KeyFile, s.opts.TLS.ClientCAFile} {
+		if fi, err := os.Stat(file); err == nil {
+			mod[i] = fi.ModTime()
+		}
+	}
+
+	return mod
+}
+
+// watch reloads the TLS files whenever they change, until ctx is done.
+func (s *Server) watch(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.TLS.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if loaded := s.loaded.Load(); loaded != nil && *loaded == s.modTimes() {
+			continue
+		}
+
+		c := s.conductor.Load()
+		if err := s.loadTLS(); err != nil {
+			if c != nil {
+				c.log.Warn("failed to reload certificate", "process", s.name, "error", err)
+			}
+
+			// Retry the same files next time only once they change again.
+			mod := s.modTimes()
+			s.loaded.Store(&mod)
+			continue
+		}
+
+		if c != nil {
+			c.log.Info("reloaded certificate", "process", s.name)
+		}
+	}
+}
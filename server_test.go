@@ -0,0 +1,24 @@
+package parallel_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/franklad/parallel"
+)
+
+func TestNewServerLeavesOptionsAlone(t *testing.T) {
+	files := &parallel.TLSFiles{CertFile: "cert.pem", KeyFile: "key.pem"}
+	opts := parallel.ServerOptions{TLS: files}
+
+	parallel.NewServer("admin", http.NotFoundHandler(), opts)
+	parallel.NewServer("health", http.NotFoundHandler(), opts)
+
+	if files.ReloadInterval != 0 {
+		t.Errorf("NewServer set ReloadInterval to %s in the caller's TLSFiles", files.ReloadInterval)
+	}
+
+	if opts.Addr != "" {
+		t.Errorf("NewServer set Addr to %q in the caller's options", opts.Addr)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build windows
+
+package parallel
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+type windowsService struct {
+	name      string
+	conductor *Conductor
+}
+
+// WindowsService returns an svc.Handler running c as the Windows service
+// called name. Stop and shutdown requests shut the conductor down
+// gracefully, pause stops every process and continue starts them again.
+// Pass the handler to svc.Run.
+func WindowsService(name string, c *Conductor) svc.Handler {
+	return &windowsService{
+		name:      name,
+		conductor: c,
+	}
+}
+
+func (s *windowsService) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	const accepts = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptPauseAndContinue
+
+	c := s.conductor
+	changes <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stopped := make(chan error, 1)
+	c.Run(ctx)
+	go func() {
+		stopped <- c.ThenStop()
+	}()
+
+	changes <- svc.Status{State: svc.Running, Accepts: accepts}
+	c.log.Info("windows service running", "service", s.name)
+
+	control := WithActor(ctx, "service control")
+
+	for {
+		select {
+		case err := <-stopped:
+			changes <- svc.Status{State: svc.StopPending}
+
+			var perr *Error
+			if err != nil || errors.As(c.shutdownCause(), &perr) {
+				return true, 1
+			}
+
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				c.audit(control, "shutdown", serviceCommand(req.Cmd), nil)
+				c.shutdown("service control: "+serviceCommand(req.Cmd), nil)
+			case svc.Pause:
+				changes <- svc.Status{State: svc.PausePending, Accepts: accepts}
+				for _, h := range c.Processes() {
+					if err := h.Stop(control); err != nil && !errors.Is(err, ErrNotRunning) {
+						c.log.Error("failed to pause process", "process", h.Name(), "error", err)
+					}
+				}
+
+				changes <- svc.Status{State: svc.Paused, Accepts: accepts}
+			case svc.Continue:
+				changes <- svc.Status{State: svc.ContinuePending, Accepts: accepts}
+				for _, h := range c.Processes() {
+					if err := h.Restart(control); err != nil && !errors.Is(err, ErrNotRunning) {
+						c.log.Error("failed to continue process", "process", h.Name(), "error", err)
+					}
+				}
+
+				changes <- svc.Status{State: svc.Running, Accepts: accepts}
+			}
+		}
+	}
+}
+
+func serviceCommand(cmd svc.Cmd) string {
+	if cmd == svc.Shutdown {
+		return "shutdown"
+	}
+
+	return "stop"
+}
@@ -0,0 +1,112 @@
+package parallel
+
+import (
+	"slices"
+	"sync"
+)
+
+// Shared is a concurrent key/value store owned by the conductor, for small
+// read-mostly state that processes share, such as the current config
+// snapshot or feature flags. Values are read and written through typed
+// keys with Get and Set, and every change is published on the conductor's
+// bus on the key's topic.
+type Shared struct {
+	mu     sync.RWMutex
+	values map[string]any
+	bus    *Bus
+}
+
+// Key names a value of type T in Shared.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey returns the key called name.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// Name returns the name of the key.
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Topic returns the bus topic on which the new values of k are published.
+func (k Key[T]) Topic() Topic[T] {
+	return NewTopic[T]("shared:" + k.name)
+}
+
+// Shared returns the conductor's shared state. It outlives individual runs.
+func (c *Conductor) Shared() *Shared {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.shared == nil {
+		c.shared = &Shared{bus: c.eventBus()}
+	}
+
+	return c.shared
+}
+
+// Get returns the value of key, or false if it is unset or holds a value
+// of another type.
+func Get[T any](s *Shared, key Key[T]) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.values[key.name].(T)
+	return v, ok
+}
+
+// Set stores v under key and publishes it on key.Topic().
+func Set[T any](s *Shared, key Key[T], v T) {
+	s.mu.Lock()
+	if s.values == nil {
+		s.values = make(map[string]any)
+	}
+
+	s.values[key.name] = v
+	s.mu.Unlock()
+
+	Publish(s.bus, key.Topic(), v)
+}
+
+// Update replaces the value of key with fn applied to its current value, or
+// to the zero value and false if unset, and publishes the result. Concurrent
+// updates of the same store are serialized.
+func Update[T any](s *Shared, key Key[T], fn func(v T, ok bool) T) T {
+	s.mu.Lock()
+	if s.values == nil {
+		s.values = make(map[string]any)
+	}
+
+	old, ok := s.values[key.name].(T)
+	v := fn(old, ok)
+	s.values[key.name] = v
+	s.mu.Unlock()
+
+	Publish(s.bus, key.Topic(), v)
+	return v
+}
+
+// Delete removes the value of key. Deletions are not published.
+func Delete[T any](s *Shared, key Key[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.values, key.name)
+}
+
+// Keys returns the names of the keys that are set.
+func (s *Shared) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+	return keys
+}
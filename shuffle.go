@@ -0,0 +1,45 @@
+package parallel
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// WithShuffledStart starts the processes in a random order instead of the
+// order they were registered in, to shake out hidden ordering assumptions
+// in CI and to spread the load when many replicas boot at once. A process
+// still waits for its dependencies. The order is derived from seed, so a
+// failing order can be reproduced; a seed of 0 picks a new one for every
+// run. The seed and resulting order are logged.
+func WithShuffledStart(seed uint64) Option {
+	return func(c *Conductor) {
+		c.shuffle = true
+		c.shuffleSeed = seed
+	}
+}
+
+// startOrder returns the entries in the order to launch them. The caller
+// must hold c.mu.
+func (c *Conductor) startOrder() []*entry {
+	if !c.shuffle {
+		return c.entries
+	}
+
+	seed := c.shuffleSeed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+
+	order := slices.Clone(c.entries)
+	rand.New(rand.NewPCG(seed, seed)).Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+
+	names := make([]string, len(order))
+	for i, e := range order {
+		names[i] = e.name()
+	}
+
+	c.log.Info("shuffled start order", "seed", seed, "order", names)
+	return order
+}
@@ -0,0 +1,516 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	shutdownTimeout          = 5 * time.Second
+	shutdownProgressInterval = time.Second
+
+	// runExitGrace is how long the conductor waits at least for Run to
+	// return after Stop, even when the shutdown budget is used up.
+	runExitGrace = 100 * time.Millisecond
+)
+
+// ShutdownPolicy controls a shutdown triggered by a particular signal. Name
+// is added to the shutdown reason, Timeout bounds the whole shutdown and
+// defaults to 5s, and Concurrency limits how many processes of a dependency
+// level stop at once, with 0 meaning no limit.
+type ShutdownPolicy struct {
+	Name        string
+	Timeout     time.Duration
+	Concurrency int
+}
+
+// WithShutdownSignal makes sig trigger a graceful shutdown using policy.
+// SIGINT and SIGTERM are always registered with the default policy unless
+// overridden here. Shutdowns caused by process failures or context
+// cancellation use the SIGTERM policy.
+func WithShutdownSignal(sig os.Signal, policy ShutdownPolicy) Option {
+	return func(c *Conductor) {
+		if c.signals == nil {
+			c.signals = make(map[os.Signal]ShutdownPolicy)
+		}
+
+		c.signals[sig] = policy
+	}
+}
+
+// shutdownSignals returns every signal that triggers a shutdown. The caller
+// must hold c.mu.
+func (c *Conductor) shutdownSignals() []os.Signal {
+	sigs := []os.Signal{sigInterrupt, sigTerminate}
+	for sig := range c.signals {
+		if sig != sigInterrupt && sig != sigTerminate {
+			sigs = append(sigs, sig)
+		}
+	}
+
+	return sigs
+}
+
+// policy returns the shutdown policy for sig. The caller must hold c.mu.
+func (c *Conductor) policy(sig os.Signal) ShutdownPolicy {
+	p := c.signals[sig]
+	if p.Timeout <= 0 {
+		p.Timeout = shutdownTimeout
+	}
+
+	return p
+}
+
+// WithShutdownHook calls fn with the shutdown cause as soon as shutdown
+// begins, before any process is stopped, so request handlers can start
+// shedding load straight away. Hooks run synchronously and must not block.
+func WithShutdownHook(fn func(cause error)) Option {
+	return func(c *Conductor) {
+		c.shutdownHooks = append(c.shutdownHooks, fn)
+	}
+}
+
+// Draining reports whether the conductor has begun shutting down. It is a
+// single atomic load, cheap enough to check on every request.
+func (c *Conductor) Draining() bool {
+	return c.draining.Load()
+}
+
+// WithStopCompleted makes the conductor call Stop during shutdown even on
+// processes whose Run already returned without an error. By default those
+// are reported as completed in the shutdown summary and not stopped again.
+func WithStopCompleted() Option {
+	return func(c *Conductor) {
+		c.stopCompleted = true
+	}
+}
+
+// WithStopUnstarted makes the conductor call Stop during shutdown even on
+// processes that never started because they were still waiting for their
+// dependencies. By default those are only marked ProcessNeverStarted.
+func WithStopUnstarted() Option {
+	return func(c *Conductor) {
+		c.stopUnstarted = true
+	}
+}
+
+// WithContextOnly stops the conductor from subscribing to OS signals, so it
+// is driven purely by the context given to Run. This suits a conductor
+// nested inside another framework that owns signal handling.
+func WithContextOnly() Option {
+	return func(c *Conductor) {
+		c.contextOnly = true
+	}
+}
+
+// ErrMaxRuntime is the shutdown cause when the conductor has run for longer
+// than allowed by WithMaxRuntime.
+var ErrMaxRuntime = errors.New("maximum runtime exceeded")
+
+// WithMaxRuntime bounds how long each run of the conductor may last. Once d
+// has elapsed since Run, a graceful shutdown begins with ErrMaxRuntime as
+// its cause, which suits batch jobs and cron-invoked binaries that would
+// otherwise rely on an external kill timer.
+func WithMaxRuntime(d time.Duration) Option {
+	return func(c *Conductor) {
+		c.maxRuntime = d
+	}
+}
+
+func (c *Conductor) enforceMaxRuntime(d time.Duration, done <-chan struct{}) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return
+	case <-timer.C:
+	}
+
+	c.log.Warn("maximum runtime exceeded", "max_runtime", d)
+	c.shutdown(fmt.Sprintf("maximum runtime of %s exceeded", d), ErrMaxRuntime)
+}
+
+// WithBaseContext makes the Run context of every process derive from the
+// context fn returns, like http.Server.BaseContext, so processes see the
+// values the application put there, such as tenant information or a
+// dependency container. The context given to Conductor.Run still shuts the
+// conductor down when it is cancelled, and so does cancelling the base.
+func WithBaseContext(fn func() context.Context) Option {
+	return func(c *Conductor) {
+		c.baseContext = fn
+	}
+}
+
+// runContext returns the context a run uses, deriving it from the base
+// context when one is configured. The caller must hold c.mu.
+func (c *Conductor) runContext(ctx context.Context) context.Context {
+	if c.baseContext == nil {
+		return ctx
+	}
+
+	base := c.baseContext()
+	if base == nil {
+		panic("parallel: BaseContext returned a nil context")
+	}
+
+	run, cancel := context.WithCancelCause(base)
+	stop := context.AfterFunc(ctx, func() {
+		cancel(context.Cause(ctx))
+	})
+
+	c.cleanups = append(c.cleanups, func() {
+		stop()
+		cancel(nil)
+	})
+
+	return run
+}
+
+type causeKey struct{}
+
+// ShutdownCause returns why the conductor is shutting down when called with
+// the context passed to Stop or Drain during shutdown. When the shutdown
+// was caused by cancellation of the Run context, it is that context's
+// cancellation cause; when a process failed, it is the *Error. It returns
+// nil outside of a conductor shutdown.
+func ShutdownCause(ctx context.Context) error {
+	err, _ := ctx.Value(causeKey{}).(error)
+	return err
+}
+
+// shutdownCause returns the cause of the current or last shutdown.
+func (c *Conductor) shutdownCause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cause
+}
+
+// drainAndStop drains e if it implements Drainer and then stops it, bounded
+// by its StopTimeout if it implements StopTimeouter.
+func (c *Conductor) drainAndStop(ctx context.Context, e *entry) error {
+	if t, ok := as[StopTimeouter](e.process); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.StopTimeout())
+		defer cancel()
+	}
+
+	if d, ok := as[Drainer](e.process); ok {
+		if err := d.Drain(ctx); err != nil {
+			c.log.Warn("failed to drain process", "process", e.name(), "error", err)
+		}
+	}
+
+	return e.process.Stop(ctx)
+}
+
+// ShutdownWeighter is implemented by processes that need a larger or
+// smaller share of the shutdown budget than others. Weights are relative
+// and default to 1.
+type ShutdownWeighter interface {
+	ShutdownWeight() float64
+}
+
+type weightedProcess struct {
+	Process
+	weight float64
+}
+
+// WithShutdownWeight gives p the relative weight w when the shutdown budget
+// is divided between processes.
+func WithShutdownWeight(p Process, w float64) Process {
+	return &weightedProcess{
+		Process: p,
+		weight:  w,
+	}
+}
+
+func (w *weightedProcess) ShutdownWeight() float64 {
+	return w.weight
+}
+
+func (w *weightedProcess) Unwrap() Process {
+	return w.Process
+}
+
+func shutdownWeight(p Process) float64 {
+	if w, ok := as[ShutdownWeighter](p); ok && w.ShutdownWeight() > 0 {
+		return w.ShutdownWeight()
+	}
+
+	return 1
+}
+
+type stopResult struct {
+	name      string
+	budget    time.Duration
+	duration  time.Duration
+	err       *Error
+	completed bool
+	// unstarted is set for processes that never started.
+	unstarted bool
+}
+
+// stopAll stops the given dependency levels in reverse, so a process stops
+// before the processes it depends on. Processes within a level stop
+// concurrently, up to policy.Concurrency at a time, and the ones still
+// stopping are logged every shutdownProgressInterval until all of them
+// return.
+//
+// Each level gets a share of the budget that remains when it starts
+// stopping, weighted by the heaviest process in it, so time left over by
+// levels that stop early goes to the levels after them. Within a level, a
+// process gets the part of the level's share matching its weight relative
+// to the heaviest one, capped further by its own StopTimeout.
+func (c *Conductor) stopAll(ctx context.Context, levels [][]*entry, policy ShutdownPolicy, cause error) []stopResult {
+	ctx, cancel := context.WithTimeout(context.WithValue(ctx, causeKey{}, cause), policy.Timeout)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		pending = make(map[*entry]time.Time)
+		results []stopResult
+	)
+
+	stop := func(ctx context.Context, e *entry, deadline time.Time) {
+		state, _ := e.status()
+		if state == ProcessStopped {
+			return
+		}
+
+		if state == ProcessExited && !c.stopCompleted {
+			mu.Lock()
+			results = append(results, stopResult{name: e.name(), completed: true})
+			mu.Unlock()
+
+			c.log.Info("process already completed, not stopping it", "process", e.name())
+			return
+		}
+
+		if !e.started() && !c.stopUnstarted {
+			e.setState(ProcessNeverStarted)
+
+			mu.Lock()
+			results = append(results, stopResult{name: e.name(), unstarted: true})
+			mu.Unlock()
+
+			c.log.Info("process never started, not stopping it", "process", e.name())
+			return
+		}
+
+		ctx, cancel := context.WithDeadline(ctx, deadline)
+		defer cancel()
+
+		began := time.Now()
+
+		mu.Lock()
+		pending[e] = began
+		mu.Unlock()
+
+		e.transition(ProcessStopping, ProcessWaiting, ProcessStarting, ProcessRunning, ProcessRestarting)
+
+		var budget time.Duration
+		if deadline, ok := ctx.Deadline(); ok {
+			budget = time.Until(deadline)
+		}
+
+		if t, ok := as[StopTimeouter](e.process); ok && t.StopTimeout() < budget {
+			budget = t.StopTimeout()
+		}
+
+		var perr *Error
+		if err := c.drainAndStop(ctx, e); err != nil {
+			perr = wrapError(OpStop, e.process, err)
+		}
+
+		e.transition(ProcessStopped, ProcessStopping)
+
+		r := stopResult{
+			name:     e.name(),
+			budget:   budget,
+			duration: time.Since(began),
+			err:      perr,
+		}
+
+		mu.Lock()
+		delete(pending, e)
+		results = append(results, r)
+		mu.Unlock()
+
+		if perr != nil {
+			c.log.Error("failed to stop process", "process", r.name, "duration", r.duration, "budget", r.budget, "error", perr.Err)
+			c.report(ctx, perr)
+			c.emit(Event{Type: EventProcessStopFailed, Process: r.name, Duration: r.duration, Err: perr})
+		} else {
+			c.log.Info("stopped process", "process", r.name, "duration", r.duration, "budget", r.budget)
+			c.emit(Event{Type: EventProcessStopped, Process: r.name, Duration: r.duration})
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer c.crashGuard("shutdown")
+
+		weights := make([]float64, len(levels))
+		for i, level := range levels {
+			for _, e := range level {
+				weights[i] = max(weights[i], shutdownWeight(e.process))
+			}
+		}
+
+		for i := len(levels) - 1; i >= 0; i-- {
+			limit := policy.Concurrency
+			if limit <= 0 {
+				limit = len(levels[i])
+			}
+
+			sem := NewSemaphore(limit)
+
+			var remaining float64
+			for _, w := range weights[:i+1] {
+				remaining += w
+			}
+
+			deadline, _ := ctx.Deadline()
+			began := time.Now()
+			share := float64(time.Until(deadline)) * weights[i] / remaining
+
+			var wg sync.WaitGroup
+			for _, e := range levels[i] {
+				wg.Add(1)
+				go func(e *entry) {
+					defer wg.Done()
+					defer c.crashGuard("shutdown")
+
+					// Once the budget is spent, waiting for a slot would
+					// only stall the remaining stops, which fail fast anyway.
+					if sem.Acquire(ctx) == nil {
+						defer sem.Release()
+					}
+
+					d := time.Duration(share * shutdownWeight(e.process) / weights[i])
+					stop(ctx, e, began.Add(d))
+				}(e)
+			}
+
+			wg.Wait()
+		}
+	}()
+
+	ticker := time.NewTicker(shutdownProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return results
+		case <-ticker.C:
+			mu.Lock()
+			for e, began := range pending {
+				c.log.Warn("process still stopping", "process", e.name(), "elapsed", time.Since(began))
+			}
+			mu.Unlock()
+		}
+	}
+}
+
+// watchSecondSignal forces the shutdown on a stop signal received before it
+// finishes: the Stop contexts are cancelled and ThenStop no longer waits
+// for Run to return. With WithCrashReport it crashes instead.
+func (c *Conductor) watchSecondSignal(stop <-chan os.Signal, finished <-chan struct{}, force context.CancelFunc) {
+	select {
+	case sig := <-stop:
+		if c.crashDir != "" {
+			c.crash("received "+sig.String()+" during shutdown", nil, nil)
+		}
+
+		c.log.Warn("received second stop signal, forcing shutdown", "signal", sig.String())
+		force()
+	case <-finished:
+	}
+}
+
+// inflight counts goroutines in flight, such as the Run of every process.
+// Unlike a sync.WaitGroup, it can be waited on with a timeout and counted up
+// again while a waiter that gave up is gone.
+type inflight struct {
+	mu   sync.Mutex
+	n    int
+	zero chan struct{}
+}
+
+var closedChan = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+func (f *inflight) add() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.n == 0 {
+		f.zero = make(chan struct{})
+	}
+
+	f.n++
+}
+
+func (f *inflight) done() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.n--
+	if f.n == 0 {
+		close(f.zero)
+	}
+}
+
+// idle returns a channel closed once nothing is in flight.
+func (f *inflight) idle() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.n == 0 {
+		return closedChan
+	}
+
+	return f.zero
+}
+
+// awaitRuns waits until the Run of every process has returned, for the rest
+// of the shutdown budget ending at deadline but at least runExitGrace, so
+// that no process is still working when ThenStop returns. It returns the
+// names of the processes in levels whose Run is still executing after
+// their Stop returned. It gives up at once when ctx is done.
+func (c *Conductor) awaitRuns(ctx context.Context, levels [][]*entry, deadline time.Time) []string {
+	returned := c.runs.idle()
+
+	timer := time.NewTimer(max(time.Until(deadline), runExitGrace))
+	defer timer.Stop()
+
+	select {
+	case <-returned:
+		return nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	var running []string
+	for _, level := range levels {
+		for _, e := range level {
+			if e.running() {
+				running = append(running, e.name())
+				c.log.Warn("process still running after stop", "process", e.name())
+			}
+		}
+	}
+
+	return running
+}
@@ -0,0 +1,42 @@
+package parallel_test
+
+import (
+	"context"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/franklad/parallel"
+	"github.com/franklad/parallel/conductortest"
+)
+
+func TestRunAgainWhileAbandonedRunIsStillExecuting(t *testing.T) {
+	var first atomic.Bool
+	first.Store(true)
+	p := parallel.Task("stubborn", func(ctx context.Context) error {
+		if first.CompareAndSwap(true, false) {
+			time.Sleep(500 * time.Millisecond) // ignores being stopped
+			return nil
+		}
+
+		<-ctx.Done()
+		return nil
+	})
+
+	c := conductortest.New(p).With(
+		parallel.WithLogger(discard()),
+		parallel.WithShutdownSignal(syscall.SIGTERM, parallel.ShutdownPolicy{Timeout: 50 * time.Millisecond}),
+	)
+
+	for range 2 {
+		c.Run(context.Background())
+		c.Shutdown("test")
+		if err := c.ThenStop(); err != nil {
+			t.Fatalf("ThenStop: %v", err)
+		}
+	}
+
+	// Let the abandoned Run return while nothing waits for it.
+	time.Sleep(600 * time.Millisecond)
+}
@@ -0,0 +1,103 @@
+package parallel
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// signalSource delivers OS signals to the conductor. It is the os/signal
+// package unless WithVirtualSignals swaps in virtualSignals.
+type signalSource interface {
+	Notify(ch chan<- os.Signal, sigs ...os.Signal)
+	Stop(ch chan<- os.Signal)
+}
+
+type osSignals struct{}
+
+func (osSignals) Notify(ch chan<- os.Signal, sigs ...os.Signal) { signal.Notify(ch, sigs...) }
+func (osSignals) Stop(ch chan<- os.Signal)                      { signal.Stop(ch) }
+
+// ErrRealSignals is returned by SendVirtualSignal for a conductor that
+// listens for OS signals.
+var ErrRealSignals = errors.New("conductor receives OS signals, not virtual ones")
+
+// WithVirtualSignals stops the conductor from listening for OS signals.
+// Signals sent with SendVirtualSignal go through the same handling
+// instead, shutdown policies, Signaler and Reloader included. This lets
+// integration tests exercise signal handling without signalling the test
+// binary; see the conductortest package.
+func WithVirtualSignals() Option {
+	return func(c *Conductor) {
+		c.sigsrc = &virtualSignals{}
+	}
+}
+
+// SendVirtualSignal delivers sig to a conductor created with
+// WithVirtualSignals as if the OS had sent it. It reports whether anything
+// was listening for sig, which is not the case before Run, after ThenStop,
+// or with WithContextOnly.
+func (c *Conductor) SendVirtualSignal(sig os.Signal) (bool, error) {
+	c.mu.Lock()
+	src, ok := c.sigsrc.(*virtualSignals)
+	c.mu.Unlock()
+
+	if !ok {
+		return false, ErrRealSignals
+	}
+
+	return src.Send(sig), nil
+}
+
+// virtualSignals is a signalSource whose signals are sent by Send rather than
+// the OS, so signal handling can be exercised deterministically.
+type virtualSignals struct {
+	mu   sync.Mutex
+	subs map[chan<- os.Signal][]os.Signal
+}
+
+func (f *virtualSignals) Notify(ch chan<- os.Signal, sigs ...os.Signal) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.subs == nil {
+		f.subs = make(map[chan<- os.Signal][]os.Signal)
+	}
+
+	f.subs[ch] = append(f.subs[ch], sigs...)
+}
+
+func (f *virtualSignals) Stop(ch chan<- os.Signal) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.subs, ch)
+}
+
+// Send delivers sig to every channel subscribed to it and reports whether
+// any was. Like os/signal, it does not block on a full channel, whose
+// receiver already has a signal pending, but still counts it as delivered.
+func (f *virtualSignals) Send(sig os.Signal) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var delivered bool
+	for ch, sigs := range f.subs {
+		for _, s := range sigs {
+			if s != sig {
+				continue
+			}
+
+			delivered = true
+			select {
+			case ch <- sig:
+			default:
+			}
+
+			break
+		}
+	}
+
+	return delivered
+}
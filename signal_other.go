@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package parallel
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	sigInterrupt os.Signal = os.Interrupt
+	sigTerminate os.Signal = syscall.SIGTERM
+	sigReload    os.Signal
+)
+
+var forwardedSignals []os.Signal
@@ -0,0 +1,135 @@
+package parallel
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+type stubbornProcess struct {
+	stopping chan struct{}
+	stopErr  chan error
+}
+
+func (p *stubbornProcess) Name() string { return "stubborn" }
+
+func (p *stubbornProcess) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+// Stop only returns once its context is done.
+func (p *stubbornProcess) Stop(ctx context.Context) error {
+	close(p.stopping)
+	<-ctx.Done()
+	p.stopErr <- ctx.Err()
+	return ctx.Err()
+}
+
+func newSignalTestConductor(p Process, timeout time.Duration) (*Conductor, *virtualSignals) {
+	c := NewConductor(p).With(
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		WithVirtualSignals(),
+		WithShutdownSignal(sigTerminate, ShutdownPolicy{Timeout: timeout}),
+	)
+
+	return c, c.sigsrc.(*virtualSignals)
+}
+
+func thenStopWithin(t *testing.T, c *Conductor, d time.Duration) {
+	t.Helper()
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- c.ThenStop() }()
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatalf("ThenStop: %v", err)
+		}
+	case <-time.After(d):
+		t.Fatalf("ThenStop did not return within %s", d)
+	}
+}
+
+func TestSignalTriggersShutdown(t *testing.T) {
+	c, sigs := newSignalTestConductor(Task("task", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}), time.Second)
+
+	if sigs.Send(sigTerminate) {
+		t.Fatal("signal delivered before Run")
+	}
+
+	c.Run(context.Background())
+	if !sigs.Send(sigTerminate) {
+		t.Fatal("SIGTERM not delivered")
+	}
+
+	thenStopWithin(t, c, 2*time.Second)
+
+	c.mu.Lock()
+	reason := c.reason
+	c.mu.Unlock()
+
+	if want := "signal: " + sigTerminate.String(); reason != want {
+		t.Errorf("shutdown reason = %q, want %q", reason, want)
+	}
+
+	if sigs.Send(sigTerminate) {
+		t.Error("signal delivered after ThenStop")
+	}
+}
+
+func TestSecondSignalForcesShutdown(t *testing.T) {
+	p := &stubbornProcess{stopping: make(chan struct{}), stopErr: make(chan error, 1)}
+	c, sigs := newSignalTestConductor(p, time.Minute)
+
+	c.Run(context.Background())
+	sigs.Send(sigTerminate)
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- c.ThenStop() }()
+
+	select {
+	case <-p.stopping:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop was not called")
+	}
+
+	if !sigs.Send(sigTerminate) {
+		t.Fatal("second SIGTERM not delivered")
+	}
+
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatalf("ThenStop: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("second signal did not force the shutdown")
+	}
+
+	if err := <-p.stopErr; err != context.Canceled {
+		t.Errorf("Stop context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestSendToFullChannel(t *testing.T) {
+	var sigs virtualSignals
+
+	ch := make(chan os.Signal, 1)
+	sigs.Notify(ch, sigTerminate)
+
+	if !sigs.Send(sigTerminate) || !sigs.Send(sigTerminate) {
+		t.Fatal("signal to a subscribed channel with a signal pending not reported as delivered")
+	}
+
+	if len(ch) != 1 {
+		t.Errorf("%d signals pending, want 1", len(ch))
+	}
+}
@@ -0,0 +1,16 @@
+//go:build unix
+
+package parallel
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	sigInterrupt os.Signal = syscall.SIGINT
+	sigTerminate os.Signal = syscall.SIGTERM
+	sigReload    os.Signal = syscall.SIGHUP
+)
+
+var forwardedSignals = []os.Signal{syscall.SIGUSR1, syscall.SIGUSR2}
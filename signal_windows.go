@@ -0,0 +1,18 @@
+//go:build windows
+
+package parallel
+
+import (
+	"os"
+	"syscall"
+)
+
+// The Go runtime delivers Ctrl+C as os.Interrupt and console close, logoff
+// and shutdown events as SIGTERM. Windows has no reload or user signals.
+var (
+	sigInterrupt os.Signal = os.Interrupt
+	sigTerminate os.Signal = syscall.SIGTERM
+	sigReload    os.Signal
+)
+
+var forwardedSignals []os.Signal
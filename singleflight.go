@@ -0,0 +1,105 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+)
+
+// Singleflight coalesces the expensive startup steps that several processes
+// of the conductor perform, such as fetching a config bundle or warming a
+// shared cache, into one execution whose result they all receive. Calls
+// are made with Coalesce.
+type Singleflight struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done    chan struct{}
+	val     any
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// Singleflight returns the conductor's singleflight group. Results are
+// remembered until the run of the conductor ends.
+func (c *Conductor) Singleflight() *Singleflight {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.flights == nil {
+		c.flights = &Singleflight{}
+	}
+
+	return c.flights
+}
+
+// Coalesce returns the result of fn for key, running it only if no other
+// call for key has succeeded or is in flight. A failed call is not
+// remembered, so the next caller runs fn again. fn's context is cancelled
+// once every caller waiting for it has given up, so one process stopping
+// does not abort the work the others wait for.
+func Coalesce[T any](ctx context.Context, g *Singleflight, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flight)
+	}
+
+	f, ok := g.calls[key]
+	if !ok {
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = f
+
+		go func() {
+			defer cancel()
+
+			v, err := fn(fctx)
+			g.mu.Lock()
+			defer g.mu.Unlock()
+
+			f.val, f.err = v, err
+			if err != nil && g.calls[key] == f {
+				delete(g.calls, key)
+			}
+
+			close(f.done)
+		}()
+	}
+
+	f.waiters++
+	g.mu.Unlock()
+
+	var zero T
+	select {
+	case <-f.done:
+		if f.err != nil {
+			return zero, f.err
+		}
+
+		v, _ := f.val.(T)
+		return v, nil
+	case <-ctx.Done():
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		f.waiters--
+		if f.waiters == 0 && !isClosed(f.done) {
+			f.cancel()
+			if g.calls[key] == f {
+				delete(g.calls, key)
+			}
+		}
+
+		return zero, ctx.Err()
+	}
+}
+
+// forget drops the remembered results.
+func (g *Singleflight) forget() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.calls = nil
+}
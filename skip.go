@@ -0,0 +1,182 @@
+package parallel
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+// OnlyEnv and SkipEnv name the environment variables holding the
+// comma-separated names of the processes to run exclusively and the ones
+// not to run, when WithProcessFilter does not set them.
+const (
+	OnlyEnv = "PARALLEL_ONLY"
+	SkipEnv = "PARALLEL_SKIP"
+)
+
+// Enabler is implemented by processes that can be switched off, for
+// example by a feature flag. Enabled is checked every time the process
+// would be started by Run or Add; a disabled process is reported as skipped.
+// It is called with the conductor locked, so it must not call back into
+// the conductor.
+type Enabler interface {
+	Enabled() bool
+}
+
+type conditionalProcess struct {
+	Process
+	enabled func() bool
+}
+
+// EnabledIf makes p run only when enabled returns true, for processes that
+// do not implement Enabler themselves.
+func EnabledIf(p Process, enabled func() bool) Process {
+	return &conditionalProcess{
+		Process: p,
+		enabled: enabled,
+	}
+}
+
+func (p *conditionalProcess) Enabled() bool {
+	return p.enabled()
+}
+
+func (p *conditionalProcess) Unwrap() Process {
+	return p.Process
+}
+
+// WithProcessFilter runs only the processes named in only, when it is not
+// empty, and none of the processes named in skip. Each list takes
+// precedence over PARALLEL_ONLY and PARALLEL_SKIP respectively when it is
+// not nil.
+func WithProcessFilter(only, skip []string) Option {
+	return func(c *Conductor) {
+		c.only, c.skipList = only, skip
+	}
+}
+
+// processFilter returns the only and skip lists in effect. The caller must
+// hold c.mu.
+func (c *Conductor) processFilter() (only, skip []string) {
+	only, skip = c.only, c.skipList
+	if only == nil {
+		only = splitList(os.Getenv(OnlyEnv))
+	}
+
+	if skip == nil {
+		skip = splitList(os.Getenv(SkipEnv))
+	}
+
+	return only, skip
+}
+
+// checkFilter warns about names in the process filter that match no
+// process, which are usually typos. The caller must hold c.mu.
+func (c *Conductor) checkFilter() {
+	only, skip := c.processFilter()
+	if len(only) == 0 && len(skip) == 0 {
+		return
+	}
+
+	c.log.Info("filtering processes", "only", only, "skip", skip)
+
+	for _, name := range append(only, skip...) {
+		if c.find(name) == nil {
+			c.log.Warn("process filter names an unknown process", "process", name)
+		}
+	}
+}
+
+// needed reports whether the process called name is in only or is a
+// dependency, direct or not, of a process in only. The caller must hold
+// c.mu.
+func (c *Conductor) needed(name string, only []string) bool {
+	seen := make(map[string]bool)
+
+	var visit func(n string) bool
+	visit = func(n string) bool {
+		if n == name {
+			return true
+		}
+
+		if seen[n] {
+			return false
+		}
+
+		seen[n] = true
+		if e := c.find(n); e != nil {
+			for _, dep := range dependsOn(e.process) {
+				if visit(dep) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	return slices.ContainsFunc(only, visit)
+}
+
+// skipReason returns why e must not run in the current run, or "" when it
+// should. The caller must hold c.mu.
+func (c *Conductor) skipReason(e *entry) string {
+	only, skip := c.processFilter()
+	switch {
+	case slices.Contains(skip, e.name()):
+		return "excluded by process filter"
+	case len(only) > 0 && !e.builtin && !c.needed(e.name(), only):
+		return "not selected by process filter"
+	case !c.profileActive(e.process):
+		return "profile not active"
+	}
+
+	if en, ok := as[Enabler](e.process); ok && !en.Enabled() {
+		return "disabled"
+	}
+
+	return ""
+}
+
+// skip reports whether e must not run in the current run. Processes that
+// depend on a skipped process are skipped too. A skipped process is marked
+// as such and logged once per run. The caller must hold c.mu.
+func (c *Conductor) skip(e *entry) bool {
+	if reason, ok := c.skipped[e]; ok {
+		return reason != ""
+	}
+
+	reason := c.skipReason(e)
+	if reason == "" {
+		for _, dep := range dependsOn(e.process) {
+			if c.skip(c.find(dep)) {
+				reason = "depends on skipped process " + dep
+				break
+			}
+		}
+	}
+
+	if c.skipped == nil {
+		c.skipped = make(map[*entry]string)
+	}
+
+	c.skipped[e] = reason
+	if reason == "" {
+		return false
+	}
+
+	e.setState(ProcessSkipped)
+	c.log.Info("skipping process", "process", e.name(), "reason", reason)
+	return true
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,85 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// startRateLogInterval is how often throttled starts are logged.
+const startRateLogInterval = time.Second
+
+// WithStartRate limits process starts to perSecond, allowing bursts of up
+// to burst starts at once, so a conductor managing dozens of processes does
+// not overwhelm shared infrastructure at boot. A process waiting for its
+// turn stays in ProcessStarting, and the progress of throttled starts is
+// logged every second. Restarts take their turn like any other start.
+func WithStartRate(perSecond float64, burst int) Option {
+	return func(c *Conductor) {
+		if perSecond <= 0 {
+			c.startRate = nil
+			return
+		}
+
+		c.startRate = &startLimiter{
+			interval: time.Duration(float64(time.Second) / perSecond),
+			burst:    max(burst, 1),
+		}
+	}
+}
+
+// startLimiter is a token bucket of starts, tracking the time at which the
+// bucket would be empty again.
+type startLimiter struct {
+	interval time.Duration
+	burst    int
+
+	mu      sync.Mutex
+	tat     time.Time
+	started int
+	waiting int
+	logged  time.Time
+}
+
+// waitStartTurn waits for the turn of the next start under WithStartRate,
+// or until ctx is done.
+func (c *Conductor) waitStartTurn(ctx context.Context, name string) {
+	l := c.startRate
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.tat.Before(now) {
+		l.tat = now
+	}
+
+	at := l.tat.Add(-time.Duration(l.burst-1) * l.interval)
+	l.tat = l.tat.Add(l.interval)
+	l.waiting++
+	l.mu.Unlock()
+
+	if d := time.Until(at); d > 0 {
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	l.mu.Lock()
+	l.waiting--
+	l.started++
+	waiting, started := l.waiting, l.started
+	report := waiting > 0 && time.Since(l.logged) >= startRateLogInterval
+	if report {
+		l.logged = time.Now()
+	}
+	l.mu.Unlock()
+
+	if report {
+		c.log.Info("throttling process starts", "process", name, "started", started, "waiting", waiting)
+	}
+}
@@ -0,0 +1,187 @@
+package parallel
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+const startupSummarySize = 5
+
+// Readier is implemented by processes that need time after Run is called
+// before they can do useful work. Ready blocks until the process is ready
+// or ctx is done.
+type Readier interface {
+	Ready(ctx context.Context) error
+}
+
+// Warmer is implemented by processes that need a warmup step, such as
+// priming caches or filling connection pools, after they report ready and
+// before the conductor counts them as ready.
+type Warmer interface {
+	Warmup(ctx context.Context) error
+}
+
+// StartupTiming is the startup of a process. TimeToReady includes Warmup,
+// the time spent in the process's warmup step.
+type StartupTiming struct {
+	Process     string
+	TimeToStart time.Duration
+	TimeToReady time.Duration
+	Warmup      time.Duration
+}
+
+type StartupReport struct {
+	Complete  bool
+	Duration  time.Duration
+	Processes []StartupTiming
+}
+
+// Slowest returns up to n processes ordered by how long they took to become
+// ready, slowest first.
+func (r StartupReport) Slowest(n int) []StartupTiming {
+	timings := append([]StartupTiming(nil), r.Processes...)
+	sort.SliceStable(timings, func(i, j int) bool {
+		return timings[i].TimeToReady > timings[j].TimeToReady
+	})
+
+	if n < len(timings) {
+		timings = timings[:n]
+	}
+
+	return timings
+}
+
+type startupTracker struct {
+	began time.Time
+
+	mu      sync.Mutex
+	pending int
+	report  StartupReport
+}
+
+func newStartupTracker() *startupTracker {
+	return &startupTracker{began: time.Now()}
+}
+
+// expect registers a process whose readiness is part of startup. It returns
+// false once startup has already completed.
+func (t *startupTracker) expect() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.report.Complete {
+		return false
+	}
+
+	t.pending++
+	return true
+}
+
+// ready records the timing of a process and reports whether it was the
+// last one startup was waiting for.
+func (t *startupTracker) ready(timing StartupTiming) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.report.Processes = append(t.report.Processes, timing)
+	t.pending--
+
+	if t.pending > 0 {
+		return false
+	}
+
+	t.report.Complete = true
+	t.report.Duration = time.Since(t.began)
+	return true
+}
+
+func (t *startupTracker) snapshot() StartupReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := t.report
+	report.Processes = append([]StartupTiming(nil), t.report.Processes...)
+	return report
+}
+
+// StartupReport returns the startup timings of the current run. Complete is
+// false until every process started with the run has become ready.
+func (c *Conductor) StartupReport() StartupReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.startup == nil {
+		return StartupReport{}
+	}
+
+	return c.startup.snapshot()
+}
+
+// awaitReady waits for a freshly started process to become ready and, when
+// t is not nil, records its timing as part of startup.
+func (c *Conductor) awaitReady(ctx context.Context, t *startupTracker, e *entry, started time.Time, errs *errorStream) {
+	defer c.crashGuard("readiness tracking")
+
+	process := e.process
+	if r, ok := as[Readier](process); ok {
+		if err := r.Ready(ctx); err != nil {
+			if ctx.Err() == nil {
+				c.fail(ctx, e, OpReady, err, errs)
+			}
+
+			return
+		}
+	}
+
+	var warmup time.Duration
+	if w, ok := as[Warmer](process); ok {
+		began := time.Now()
+		if err := w.Warmup(ctx); err != nil {
+			if ctx.Err() == nil {
+				c.fail(ctx, e, OpWarmup, err, errs)
+			}
+
+			return
+		}
+
+		warmup = time.Since(began)
+		c.emit(Event{Type: EventProcessWarmedUp, Process: process.Name(), Duration: warmup})
+	}
+
+	e.transition(ProcessRunning, ProcessStarting)
+	e.markReady()
+
+	if t == nil {
+		c.log.Info("process ready", "process", process.Name(), "time_to_ready", time.Since(started), "warmup", warmup)
+		c.emit(Event{Type: EventProcessReady, Process: process.Name(), Duration: time.Since(started)})
+		return
+	}
+
+	timing := StartupTiming{
+		Process:     process.Name(),
+		TimeToStart: started.Sub(t.began),
+		TimeToReady: time.Since(t.began),
+		Warmup:      warmup,
+	}
+
+	c.log.Info("process ready", "process", timing.Process, "time_to_start", timing.TimeToStart, "time_to_ready", timing.TimeToReady, "warmup", timing.Warmup)
+	c.emit(Event{Type: EventProcessReady, Process: timing.Process, Duration: timing.TimeToReady})
+
+	if !t.ready(timing) {
+		return
+	}
+
+	report := t.snapshot()
+	var slowest []any
+	for _, s := range report.Slowest(startupSummarySize) {
+		slowest = append(slowest, slog.Duration(s.Process, s.TimeToReady))
+	}
+
+	summary := append([]any{"duration", report.Duration, slog.Group("slowest", slowest...)}, c.startupSummary()...)
+	c.log.Info("startup complete", summary...)
+	c.emit(Event{Type: EventStartupComplete, Duration: report.Duration})
+	c.writeStartupArtifact(t)
+}
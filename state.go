@@ -0,0 +1,181 @@
+package parallel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const stateStoreTimeout = 5 * time.Second
+
+// ProcessRecord is the state of a process that is kept across restarts of
+// the binary: its restart count, the failures counted by the quarantine
+// policy and whether it is quarantined.
+type ProcessRecord struct {
+	Restarts    int         `json:"restarts"`
+	Failures    []time.Time `json:"failures,omitempty"`
+	Quarantined bool        `json:"quarantined,omitempty"`
+}
+
+// StateStore persists process records, keyed by process name.
+type StateStore interface {
+	Load(ctx context.Context) (map[string]ProcessRecord, error)
+	Save(ctx context.Context, records map[string]ProcessRecord) error
+}
+
+// FileStateStore is a StateStore keeping the records as JSON in the file at
+// Path. A missing file holds no records.
+type FileStateStore struct {
+	Path string
+}
+
+func (f *FileStateStore) Load(ctx context.Context) (map[string]ProcessRecord, error) {
+	b, err := os.ReadFile(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var records map[string]ProcessRecord
+	if err := json.Unmarshal(b, &records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// Save replaces the file atomically, so a crash while saving leaves the
+// previous records in place.
+func (f *FileStateStore) Save(ctx context.Context, records map[string]ProcessRecord) error {
+	b, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// WithStateStore keeps restart counts and quarantine state in s, so crash
+// loop detection survives a supervisor such as systemd restarting the whole
+// binary. Records are loaded when the conductor runs, and saved whenever a
+// process restarts, fails or is quarantined, and once shutdown completes.
+// A process quarantined by a previous instance stays quarantined and is not
+// started, until it is released or probed.
+func WithStateStore(s StateStore) Option {
+	return func(c *Conductor) {
+		c.store = s
+		c.listeners = append(c.listeners, func(e Event) {
+			switch e.Type {
+			case EventProcessRestarting, EventProcessFailed, EventProcessQuarantined, EventProcessReady, EventShutdownComplete:
+				c.saveState()
+			}
+		})
+	}
+}
+
+// WithStateFile is WithStateStore with a FileStateStore at path.
+func WithStateFile(path string) Option {
+	return WithStateStore(&FileStateStore{Path: path})
+}
+
+// restoreState applies the records loaded from the state store to the
+// entries. The caller must hold c.mu.
+func (c *Conductor) restoreState() {
+	if c.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), stateStoreTimeout)
+	defer cancel()
+
+	records, err := c.store.Load(ctx)
+	if err != nil {
+		c.log.Warn("failed to load process state", "error", err)
+		return
+	}
+
+	for _, e := range c.entries {
+		r, ok := records[e.name()]
+		if !ok {
+			continue
+		}
+
+		e.mu.Lock()
+		e.restarts = max(e.restarts, r.Restarts)
+		e.failures = r.Failures
+		e.mu.Unlock()
+
+		if r.Quarantined {
+			c.requarantine(e)
+		}
+	}
+}
+
+// requarantine keeps e quarantined by a previous instance out of this run.
+// The caller must hold c.mu.
+func (c *Conductor) requarantine(e *entry) {
+	var probe time.Duration
+	if c.quarantine != nil {
+		probe = c.quarantine.Probe
+	}
+
+	// A closed done channel lets release and the probe start the process as
+	// if it had failed during this run.
+	done := make(chan struct{})
+	close(done)
+
+	e.mu.Lock()
+	e.done = done
+	e.mu.Unlock()
+
+	c.markQuarantined(e, probe)
+	c.log.Warn("process is still quarantined", "process", e.name(), "probe", probe)
+}
+
+// saveState writes the records of every process to the state store.
+func (c *Conductor) saveState() {
+	c.mu.Lock()
+	store, entries := c.store, c.entries
+	c.mu.Unlock()
+
+	records := make(map[string]ProcessRecord, len(entries))
+	for _, e := range entries {
+		e.mu.Lock()
+		records[e.name()] = ProcessRecord{
+			Restarts:    e.restarts,
+			Failures:    append([]time.Time(nil), e.failures...),
+			Quarantined: e.quarantined,
+		}
+		e.mu.Unlock()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), stateStoreTimeout)
+	defer cancel()
+
+	c.saving.Lock()
+	defer c.saving.Unlock()
+
+	if err := store.Save(ctx, records); err != nil {
+		c.log.Warn("failed to save process state", "error", err)
+	}
+}
@@ -0,0 +1,117 @@
+package statsd
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/franklad/parallel"
+)
+
+var _ parallel.MetricsSink = (*Sink)(nil)
+
+type Sink struct {
+	conn    net.Conn
+	prefix  string
+	dogTags bool
+}
+
+// New returns a sink sending plain statsd over UDP to addr. Plain statsd
+// has no tags, so tag values are appended to the metric name instead.
+func New(addr, prefix string) (*Sink, error) {
+	return dial(addr, prefix, false)
+}
+
+// NewDatadog returns a sink sending DogStatsD over UDP to addr, with tags in
+// the Datadog "|#key:value" format.
+func NewDatadog(addr, prefix string) (*Sink, error) {
+	return dial(addr, prefix, true)
+}
+
+func dial(addr, prefix string, dogTags bool) (*Sink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+
+	return &Sink{
+		conn:    conn,
+		prefix:  prefix,
+		dogTags: dogTags,
+	}, nil
+}
+
+func (s *Sink) Gauge(name string, value float64, tags ...parallel.Tag) {
+	s.send(name, strconv.FormatFloat(value, 'f', -1, 64), "g", tags)
+}
+
+func (s *Sink) Count(name string, delta int64, tags ...parallel.Tag) {
+	s.send(name, strconv.FormatInt(delta, 10), "c", tags)
+}
+
+func (s *Sink) Timing(name string, d time.Duration, tags ...parallel.Tag) {
+	ms := float64(d) / float64(time.Millisecond)
+	s.send(name, strconv.FormatFloat(ms, 'f', -1, 64), "ms", tags)
+}
+
+func (s *Sink) Close() error {
+	return s.conn.Close()
+}
+
+func (s *Sink) send(name, value, kind string, tags []parallel.Tag) {
+	var b strings.Builder
+	b.WriteString(s.prefix)
+	b.WriteString(sanitize(name, false))
+
+	if !s.dogTags {
+		for _, t := range tags {
+			b.WriteByte('.')
+			b.WriteString(sanitize(t.Value, true))
+		}
+	}
+
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(kind)
+
+	if s.dogTags && len(tags) > 0 {
+		b.WriteString("|#")
+		for i, t := range tags {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+
+			b.WriteString(sanitize(t.Key, false))
+			b.WriteByte(':')
+			b.WriteString(sanitize(t.Value, false))
+		}
+	}
+
+	// Metrics are best effort; a lost datagram must not affect the service.
+	_, _ = s.conn.Write([]byte(b.String()))
+}
+
+// sanitize replaces the characters that delimit the line format, such as
+// process names or labels containing ':' or '|', with underscores. With
+// dots, it replaces dots too, which would otherwise add path segments to
+// a plain statsd name.
+func sanitize(s string, dots bool) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', '@', ',', '#', ' ', '\n', '\r':
+			return '_'
+		case '.':
+			if dots {
+				return '_'
+			}
+		}
+
+		return r
+	}, s)
+}
@@ -0,0 +1,54 @@
+package statsd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/franklad/parallel"
+)
+
+func TestSendSanitizesTags(t *testing.T) {
+	tests := []struct {
+		name string
+		dial func(addr, prefix string) (*Sink, error)
+		want string
+	}{
+		{"plain", New, "svc.process.failures.api_v2_worker_1:1|c"},
+		{"datadog", NewDatadog, "svc.process.failures:1|c|#process:api_v2.worker_1,bad_key:x_y"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			sink, err := tt.dial(conn.LocalAddr().String(), "svc")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sink.Close()
+
+			tags := []parallel.Tag{{Key: "process", Value: "api:v2.worker|1"}}
+			if tt.name == "datadog" {
+				tags = append(tags, parallel.Tag{Key: "bad|key", Value: "x,y"})
+			}
+
+			sink.Count("process.failures", 1, tags...)
+
+			buf := make([]byte, 512)
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := string(buf[:n]); got != tt.want {
+				t.Errorf("datagram = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
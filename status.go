@@ -0,0 +1,104 @@
+package parallel
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Status is the overall state of a conductor as seen by load balancers and
+// dashboards.
+type Status string
+
+const (
+	StatusIdle     Status = "idle"
+	StatusStarting Status = "starting"
+	StatusHealthy  Status = "healthy"
+	StatusDegraded Status = "degraded"
+	StatusStopping Status = "stopping"
+	StatusStopped  Status = "stopped"
+)
+
+// Serving reports whether the conductor is running, healthy or degraded.
+func (s Status) Serving() bool {
+	return s == StatusHealthy || s == StatusDegraded
+}
+
+// Status returns the overall status of the conductor. A running conductor
+// is degraded while any of its processes has failed, including processes
+// that are quarantined or whose bulkhead group was stopped, and starting
+// until every process started with the run is ready.
+func (c *Conductor) Status() Status {
+	c.mu.Lock()
+	state, startup, entries := c.state, c.startup, c.entries
+	c.mu.Unlock()
+
+	switch state {
+	case stateIdle:
+		return StatusIdle
+	case stateStopping:
+		return StatusStopping
+	case stateStopped:
+		return StatusStopped
+	}
+
+	for _, e := range entries {
+		if s, _ := e.status(); s == ProcessFailed {
+			return StatusDegraded
+		}
+	}
+
+	if startup != nil && !startup.snapshot().Complete {
+		return StatusStarting
+	}
+
+	return StatusHealthy
+}
+
+// HealthHandler serves the conductor's status and the state of every
+// process as JSON. It responds 200 while the conductor is healthy or
+// degraded and 503 otherwise; the X-Parallel-Status header carries the
+// status for load balancers that only look at headers.
+func (c *Conductor) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := c.Status()
+
+		type usage struct {
+			CPUSeconds float64 `json:"cpu_seconds"`
+			CPUPercent float64 `json:"cpu_percent"`
+			RSSBytes   uint64  `json:"rss_bytes"`
+		}
+
+		processes := make(map[string]ProcessState)
+		usages := make(map[string]usage)
+		versions := make(map[string]string)
+		progress := make(map[string]Progress)
+		for _, h := range c.Processes() {
+			processes[h.Name()] = h.State()
+			if u, ok := h.Usage(); ok {
+				usages[h.Name()] = usage{u.CPUTime.Seconds(), u.CPUPercent, u.RSS}
+			}
+
+			if v := h.Version(); v != "" {
+				versions[h.Name()] = v
+			}
+
+			if p, ok := h.Progress(); ok {
+				progress[h.Name()] = p
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Parallel-Status", string(status))
+		if !status.Serving() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		json.NewEncoder(w).Encode(struct {
+			Status    Status                  `json:"status"`
+			Processes map[string]ProcessState `json:"processes"`
+			Usage     map[string]usage        `json:"usage,omitempty"`
+			Versions  map[string]string       `json:"versions,omitempty"`
+			Progress  map[string]Progress     `json:"progress,omitempty"`
+		}{status, processes, usages, versions, progress})
+	})
+}
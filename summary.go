@@ -0,0 +1,163 @@
+package parallel
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// ShutdownOutcome is how a process ended during shutdown.
+type ShutdownOutcome string
+
+const (
+	OutcomeStopped      ShutdownOutcome = "stopped"
+	OutcomeFailed       ShutdownOutcome = "failed"
+	OutcomeCompleted    ShutdownOutcome = "completed"
+	OutcomeNeverStarted ShutdownOutcome = "never_started"
+	OutcomeStillRunning ShutdownOutcome = "still_running"
+)
+
+// ProcessShutdown is the part of a ShutdownSummary about one process. Err is
+// set when its Stop failed.
+type ProcessShutdown struct {
+	Process  string
+	Outcome  ShutdownOutcome
+	Duration time.Duration
+	Budget   time.Duration
+	Err      error
+}
+
+// ShutdownSummary is the final record of a shutdown. It is clean when every
+// process stopped or had already completed, none of them is still running
+// and no goroutine leaked.
+type ShutdownSummary struct {
+	Reason    string
+	Clean     bool
+	Duration  time.Duration
+	Processes []ProcessShutdown
+	Leaks     []Leak
+}
+
+func newShutdownSummary(reason string, d time.Duration, results []stopResult, running []string, leaks []Leak) *ShutdownSummary {
+	s := &ShutdownSummary{
+		Reason:   reason,
+		Clean:    len(leaks) == 0,
+		Duration: d,
+		Leaks:    leaks,
+	}
+
+	for _, r := range results {
+		p := ProcessShutdown{
+			Process:  r.name,
+			Outcome:  OutcomeStopped,
+			Duration: r.duration,
+			Budget:   r.budget,
+		}
+
+		switch {
+		case r.completed:
+			p.Outcome = OutcomeCompleted
+		case r.unstarted:
+			p.Outcome = OutcomeNeverStarted
+		case r.err != nil:
+			p.Outcome, p.Err = OutcomeFailed, r.err
+		case slices.Contains(running, r.name):
+			p.Outcome = OutcomeStillRunning
+		}
+
+		if p.Outcome == OutcomeFailed || p.Outcome == OutcomeStillRunning {
+			s.Clean = false
+		}
+
+		s.Processes = append(s.Processes, p)
+	}
+
+	return s
+}
+
+func (s *ShutdownSummary) MarshalJSON() ([]byte, error) {
+	type process struct {
+		Process    string          `json:"process"`
+		Outcome    ShutdownOutcome `json:"outcome"`
+		DurationMS float64         `json:"duration_ms,omitempty"`
+		BudgetMS   float64         `json:"budget_ms,omitempty"`
+		Error      string          `json:"error,omitempty"`
+	}
+
+	type leak struct {
+		Process    string `json:"process"`
+		Goroutines int    `json:"goroutines"`
+		Stack      string `json:"stack"`
+	}
+
+	record := struct {
+		Reason     string    `json:"reason"`
+		Clean      bool      `json:"clean"`
+		DurationMS float64   `json:"duration_ms"`
+		Processes  []process `json:"processes"`
+		Leaks      []leak    `json:"leaks,omitempty"`
+	}{
+		Reason:     s.Reason,
+		Clean:      s.Clean,
+		DurationMS: float64(s.Duration) / float64(time.Millisecond),
+	}
+
+	for _, p := range s.Processes {
+		r := process{
+			Process:    p.Process,
+			Outcome:    p.Outcome,
+			DurationMS: float64(p.Duration) / float64(time.Millisecond),
+			BudgetMS:   float64(p.Budget) / float64(time.Millisecond),
+		}
+
+		if p.Err != nil {
+			r.Error = p.Err.Error()
+		}
+
+		record.Processes = append(record.Processes, r)
+	}
+
+	for _, l := range s.Leaks {
+		record.Leaks = append(record.Leaks, leak(l))
+	}
+
+	return json.Marshal(record)
+}
+
+// summarize logs s as the "shutdown summary" entry and emits it as
+// EventShutdownSummary.
+func (c *Conductor) summarize(s *ShutdownSummary) {
+	outcomes := make([]any, len(s.Processes))
+	var errs []string
+	for i, p := range s.Processes {
+		outcomes[i] = slog.String(p.Process, string(p.Outcome))
+		if p.Err != nil {
+			errs = append(errs, p.Err.Error())
+		}
+	}
+
+	var leaked []string
+	for _, l := range s.Leaks {
+		if !slices.Contains(leaked, l.Process) {
+			leaked = append(leaked, l.Process)
+		}
+	}
+
+	level := slog.LevelInfo
+	if !s.Clean {
+		level = slog.LevelWarn
+	}
+
+	c.log.Log(context.Background(), level, "shutdown summary",
+		"reason", s.Reason,
+		"clean", s.Clean,
+		"duration", s.Duration,
+		slog.Group("processes", outcomes...),
+		"errors", errs,
+		"leaked", leaked,
+	)
+
+	c.emit(Event{Type: EventShutdownSummary, Duration: s.Duration, Summary: s})
+}
@@ -0,0 +1,128 @@
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// WithSystemdWatchdog registers a process that sends WATCHDOG=1 keepalives
+// to systemd at half the unit's WatchdogSec, but only while every critical
+// process is healthy, so systemd restarts a service that is alive but
+// wedged. critical names the processes that must be healthy; when empty,
+// every process registered by the user is critical. Processes that are
+// still starting count as healthy. Nothing is registered unless systemd
+// enabled the watchdog for this process.
+func WithSystemdWatchdog(critical ...string) Option {
+	return func(c *Conductor) {
+		interval, ok := watchdogInterval()
+		if !ok {
+			return
+		}
+
+		w := &systemdWatchdog{
+			conductor: c,
+			critical:  critical,
+			socket:    os.Getenv("NOTIFY_SOCKET"),
+			interval:  interval,
+		}
+
+		c.register(&periodic{
+			name:     "systemd-watchdog",
+			interval: interval,
+			tick:     w.tick,
+		})
+	}
+}
+
+// watchdogInterval returns half of WATCHDOG_USEC when it is meant for this
+// process.
+func watchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+
+	return time.Duration(usec) * time.Microsecond / 2, true
+}
+
+type systemdWatchdog struct {
+	conductor *Conductor
+	critical  []string
+	socket    string
+	interval  time.Duration
+}
+
+func (w *systemdWatchdog) tick(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, w.interval)
+	defer cancel()
+
+	if err := w.healthy(ctx); err != nil {
+		w.conductor.log.Warn("withholding systemd watchdog keepalive", "error", err)
+		return
+	}
+
+	if err := sdNotify(w.socket, "WATCHDOG=1"); err != nil {
+		w.conductor.log.Error("failed to send systemd watchdog keepalive", "error", err)
+	}
+}
+
+func (w *systemdWatchdog) healthy(ctx context.Context) error {
+	var handles []*Handle
+	if len(w.critical) == 0 {
+		for _, h := range w.conductor.Processes() {
+			if !h.entry.builtin {
+				handles = append(handles, h)
+			}
+		}
+	}
+
+	for _, name := range w.critical {
+		h, ok := w.conductor.Lookup(name)
+		if !ok {
+			return fmt.Errorf("unknown critical process %q", name)
+		}
+
+		handles = append(handles, h)
+	}
+
+	for _, h := range handles {
+		switch h.State() {
+		case ProcessIdle, ProcessWaiting, ProcessStarting, ProcessRestarting, ProcessSkipped, ProcessScheduledOff:
+			continue
+		}
+
+		if err := h.Health(ctx); err != nil {
+			return fmt.Errorf("%s: %w", h.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// sdNotify sends state to the systemd notification socket.
+func sdNotify(socket, state string) error {
+	if socket == "" {
+		return nil
+	}
+
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
@@ -0,0 +1,88 @@
+package parallel
+
+import (
+	"context"
+	"sync"
+)
+
+// Result is the outcome of one run of a task created by TaskWithResult.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// task is a Process that calls fn once per run. Stop cancels the context
+// fn was given.
+type task struct {
+	name string
+	fn   func(ctx context.Context) error
+
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	progress *progressTracker
+}
+
+// Task returns a one-shot Process called name that runs fn and exits. A
+// task that returns nil is marked exited and the rest of the conductor
+// keeps running; an error is a failure like that of any other process.
+func Task(name string, fn func(ctx context.Context) error) Process {
+	return &task{name: name, fn: fn}
+}
+
+// TaskWithResult returns a one-shot Process called name that runs fn, and a
+// channel on which the result of each run is delivered before the process
+// exits, so the application can use what the conductor computed. The
+// channel holds one result; a result that has not been received by the
+// time a restarted task finishes again is replaced by the newer one. The
+// channel is never closed.
+func TaskWithResult[T any](name string, fn func(ctx context.Context) (T, error)) (Process, <-chan Result[T]) {
+	results := make(chan Result[T], 1)
+
+	return Task(name, func(ctx context.Context) error {
+		v, err := fn(ctx)
+
+		select {
+		case <-results:
+		default:
+		}
+
+		results <- Result[T]{Value: v, Err: err}
+		return err
+	}), results
+}
+
+func (t *task) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	progress := &progressTracker{}
+
+	t.mu.Lock()
+	t.cancel, t.progress = cancel, progress
+	t.mu.Unlock()
+
+	return t.fn(withProgress(ctx, progress))
+}
+
+// Progress returns the progress fn last reported with ReportProgress.
+func (t *task) Progress() (done, total int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.progress.Progress()
+}
+
+func (t *task) Stop(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.cancel != nil {
+		t.cancel()
+	}
+
+	return nil
+}
+
+func (t *task) Name() string {
+	return t.name
+}
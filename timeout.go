@@ -0,0 +1,104 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var errRunTimeout = errors.New("run timeout exceeded")
+
+type TimeoutError struct {
+	Process string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("process %q exceeded run timeout of %s", e.Process, e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+type timeoutProcess struct {
+	Process
+	timeout time.Duration
+}
+
+// WithRunTimeout bounds how long Run of p may execute. Once the timeout
+// elapses the Run context is cancelled and a *TimeoutError is returned
+// without waiting for p to notice the cancellation. ThenStop still waits
+// for the abandoned Run to return, as it does for any other.
+func WithRunTimeout(p Process, d time.Duration) Process {
+	return &timeoutProcess{
+		Process: p,
+		timeout: d,
+	}
+}
+
+func (t *timeoutProcess) Run(ctx context.Context) error {
+	ctx, cancel := context.WithTimeoutCause(ctx, t.timeout, errRunTimeout)
+	defer cancel()
+
+	c := conductorFrom(ctx)
+	if c != nil {
+		c.runs.add()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		if c != nil {
+			defer c.runs.done()
+		}
+
+		done <- runRecovered(ctx, t.Process)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if !errors.Is(context.Cause(ctx), errRunTimeout) {
+			return <-done
+		}
+
+		select {
+		case err := <-done:
+			return err
+		default:
+		}
+
+		return &TimeoutError{
+			Process: t.Process.Name(),
+			Timeout: t.timeout,
+		}
+	}
+}
+
+func (t *timeoutProcess) Unwrap() Process {
+	return t.Process
+}
+
+type stopTimeoutProcess struct {
+	Process
+	timeout time.Duration
+}
+
+// WithStopTimeout caps how long Stop of p may take during shutdown, even
+// when more of the shutdown budget is left.
+func WithStopTimeout(p Process, d time.Duration) Process {
+	return &stopTimeoutProcess{
+		Process: p,
+		timeout: d,
+	}
+}
+
+func (t *stopTimeoutProcess) StopTimeout() time.Duration {
+	return t.timeout
+}
+
+func (t *stopTimeoutProcess) Unwrap() Process {
+	return t.Process
+}
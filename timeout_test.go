@@ -0,0 +1,31 @@
+package parallel_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/franklad/parallel"
+	"github.com/franklad/parallel/conductortest"
+)
+
+func TestThenStopWaitsForTimedOutRun(t *testing.T) {
+	var returned atomic.Bool
+	p := parallel.WithRunTimeout(parallel.Task("slow", func(ctx context.Context) error {
+		time.Sleep(300 * time.Millisecond) // ignores the cancellation
+		returned.Store(true)
+		return nil
+	}), 50*time.Millisecond)
+
+	c := conductortest.New(p).With(parallel.WithLogger(discard()))
+	c.Run(context.Background())
+
+	if err := c.ThenStop(); err != nil {
+		t.Fatalf("ThenStop: %v", err)
+	}
+
+	if !returned.Load() {
+		t.Error("ThenStop returned while the timed-out Run was still executing")
+	}
+}
@@ -0,0 +1,78 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ResourceUsage is a sample of the resources used by a supervised child.
+// CPUPercent is the share of one core used since the previous sample.
+type ResourceUsage struct {
+	CPUTime    time.Duration
+	CPUPercent float64
+	RSS        uint64
+	Sampled    time.Time
+}
+
+// UsageReporter is implemented by processes that can report the resources
+// their child uses, such as Command on Linux.
+type UsageReporter interface {
+	ResourceUsage() (ResourceUsage, error)
+}
+
+// WithChildUsage registers a process that samples the resource usage of
+// every process implementing UsageReporter each interval. Samples are
+// reported as the process.cpu_percent and process.rss_bytes gauges, on
+// Handle.Usage and by HealthHandler.
+func WithChildUsage(interval time.Duration) Option {
+	return func(c *Conductor) {
+		c.register(&periodic{
+			name:     "child-usage",
+			interval: interval,
+			tick:     c.sampleUsage,
+		})
+	}
+}
+
+func (c *Conductor) sampleUsage(context.Context) {
+	sink := c.sink()
+
+	for _, h := range c.Processes() {
+		r, ok := as[UsageReporter](h.entry.process)
+		if !ok {
+			continue
+		}
+
+		u, err := r.ResourceUsage()
+		if err != nil {
+			if !errors.Is(err, ErrNotRunning) && !errors.Is(err, ErrNotSupported) {
+				c.log.Warn("failed to sample resource usage", "process", h.Name(), "error", err)
+			}
+
+			continue
+		}
+
+		h.entry.mu.Lock()
+		if prev := h.entry.usage; !prev.Sampled.IsZero() && u.CPUTime >= prev.CPUTime {
+			if elapsed := u.Sampled.Sub(prev.Sampled); elapsed > 0 {
+				u.CPUPercent = 100 * float64(u.CPUTime-prev.CPUTime) / float64(elapsed)
+			}
+		}
+		h.entry.usage = u
+		h.entry.mu.Unlock()
+
+		tags := []Tag{{Key: "process", Value: h.Name()}}
+		sink.Gauge("process.cpu_percent", u.CPUPercent, tags...)
+		sink.Gauge("process.rss_bytes", float64(u.RSS), tags...)
+	}
+}
+
+// Usage returns the latest resource usage sample of the process, if
+// WithChildUsage has sampled it.
+func (h *Handle) Usage() (ResourceUsage, bool) {
+	h.entry.mu.Lock()
+	defer h.entry.mu.Unlock()
+
+	return h.entry.usage, !h.entry.usage.Sampled.IsZero()
+}
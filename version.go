@@ -0,0 +1,44 @@
+package parallel
+
+// Versioned is implemented by processes that report the version of the
+// component they run. The version is included in the startup summary, on
+// the Handle and by HealthHandler, the admin API and the control API.
+type Versioned interface {
+	Version() string
+}
+
+type versionedProcess struct {
+	Process
+	version string
+}
+
+// WithVersion attaches version to p, for components that do not implement
+// Versioned themselves.
+func WithVersion(p Process, version string) Process {
+	return &versionedProcess{
+		Process: p,
+		version: version,
+	}
+}
+
+func (v *versionedProcess) Version() string {
+	return v.version
+}
+
+func (v *versionedProcess) Unwrap() Process {
+	return v.Process
+}
+
+func versionOf(p Process) string {
+	if v, ok := as[Versioned](p); ok {
+		return v.Version()
+	}
+
+	return ""
+}
+
+// Version returns the version of the process, or "" if it does not report
+// one.
+func (h *Handle) Version() string {
+	return versionOf(h.entry.process)
+}
@@ -0,0 +1,194 @@
+package parallel
+
+import (
+	"context"
+	"time"
+)
+
+// Schedule decides when a windowed process may run. Active reports whether
+// the process should be running at now and when that next changes; a zero
+// time means it never does.
+type Schedule interface {
+	Active(now time.Time) (active bool, until time.Time)
+}
+
+// Windowed is implemented by processes that only run during the time
+// windows of their schedule. The conductor starts them when a window opens
+// and stops them when it closes, reporting them as scheduled off in
+// between. Other processes should not depend on a windowed process.
+type Windowed interface {
+	Schedule() Schedule
+}
+
+type windowedProcess struct {
+	Process
+	schedule Schedule
+}
+
+// InWindow makes p run only while s is active.
+func InWindow(p Process, s Schedule) Process {
+	return &windowedProcess{
+		Process:  p,
+		schedule: s,
+	}
+}
+
+func (w *windowedProcess) Schedule() Schedule {
+	return w.schedule
+}
+
+func (w *windowedProcess) Unwrap() Process {
+	return w.Process
+}
+
+func scheduleOf(p Process) Schedule {
+	if w, ok := as[Windowed](p); ok {
+		return w.Schedule()
+	}
+
+	return nil
+}
+
+type daily struct {
+	from, to time.Duration
+	days     []time.Weekday
+}
+
+// Daily returns a schedule that is active every day from the time of day
+// from until the time of day to, both measured from midnight in the
+// location of the time passed to Active. A window ending before it starts
+// runs past midnight. When days are given, only windows starting on those
+// days are active.
+func Daily(from, to time.Duration, days ...time.Weekday) Schedule {
+	return daily{from: from, to: to, days: days}
+}
+
+func (d daily) Active(now time.Time) (bool, time.Time) {
+	length := d.to - d.from
+	if length <= 0 {
+		length += 24 * time.Hour
+	}
+
+	var next time.Time
+	for day := -1; day <= 7; day++ {
+		midnight := time.Date(now.Year(), now.Month(), now.Day()+day, 0, 0, 0, 0, now.Location())
+		if !d.on(midnight.Weekday()) {
+			continue
+		}
+
+		start := midnight.Add(d.from)
+		end := start.Add(length)
+		if !now.Before(start) && now.Before(end) {
+			return true, end
+		}
+
+		if start.After(now) && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+
+	return false, next
+}
+
+func (d daily) on(day time.Weekday) bool {
+	if len(d.days) == 0 {
+		return true
+	}
+
+	for _, on := range d.days {
+		if on == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+// openWindow reports whether the windowed process e may start now, marking
+// it scheduled off otherwise, and arms the timer for the next boundary of
+// its window. The caller must hold c.mu.
+func (c *Conductor) openWindow(e *entry, s Schedule) bool {
+	active, until := s.Active(time.Now())
+	c.armWindow(e, until)
+
+	if active {
+		return true
+	}
+
+	e.mu.Lock()
+	e.done = nil
+	e.mu.Unlock()
+
+	e.setState(ProcessScheduledOff)
+	return false
+}
+
+// armWindow calls windowBoundary for e at until. The caller must hold c.mu.
+func (c *Conductor) armWindow(e *entry, until time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.window != nil {
+		e.window.Stop()
+		e.window = nil
+	}
+
+	if until.IsZero() {
+		return
+	}
+
+	done := c.done
+	e.window = time.AfterFunc(time.Until(until), func() { c.windowBoundary(e, done) })
+}
+
+// windowBoundary starts or stops the windowed process e as its window opens
+// or closes, provided the run that armed it is still going.
+func (c *Conductor) windowBoundary(e *entry, done chan struct{}) {
+	c.mu.Lock()
+	if c.state != stateRunning || c.done != done {
+		c.mu.Unlock()
+		return
+	}
+
+	active, until := scheduleOf(e.process).Active(time.Now())
+	c.armWindow(e, until)
+
+	switch {
+	case active && !e.running():
+		if !c.skip(e) && !e.isQuarantined() {
+			c.log.Info("time window opened, starting process", "process", e.name(), "until", until)
+			e.resetReady()
+			c.begin(c.ctx, e)
+		}
+	case !active && !e.running():
+		e.transition(ProcessScheduledOff, ProcessExited, ProcessStopped)
+	case !active:
+		c.mu.Unlock()
+
+		c.log.Info("time window closed, stopping process", "process", e.name(), "next", until)
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := c.stopProcess(ctx, e); err == nil {
+			e.transition(ProcessScheduledOff, ProcessStopped)
+		}
+
+		return
+	}
+
+	c.mu.Unlock()
+}
+
+// closeWindows stops the window timers of every entry. The caller must
+// hold c.mu.
+func (c *Conductor) closeWindows() {
+	for _, e := range c.entries {
+		e.mu.Lock()
+		if e.window != nil {
+			e.window.Stop()
+			e.window = nil
+		}
+		e.mu.Unlock()
+	}
+}
@@ -0,0 +1,14 @@
+module github.com/franklad/parallel/zerologhandler
+
+go 1.22
+
+require (
+	github.com/franklad/parallel v0.0.0-20261014054952-24fb49c0595f
+	github.com/rs/zerolog v1.34.0
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.19 // indirect
+	golang.org/x/sys v0.12.0 // indirect
+)
@@ -0,0 +1,118 @@
+package zerologhandler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rs/zerolog"
+
+	"github.com/franklad/parallel"
+)
+
+var _ slog.Handler = (*Handler)(nil)
+
+// Handler is a slog.Handler writing through a zerolog.Logger.
+type Handler struct {
+	logger zerolog.Logger
+	prefix string
+	attrs  []prefixedAttr
+}
+
+type prefixedAttr struct {
+	prefix string
+	attr   slog.Attr
+}
+
+func New(l zerolog.Logger) *Handler {
+	return &Handler{logger: l}
+}
+
+// WithLogger configures the conductor to log through l.
+func WithLogger(l zerolog.Logger) parallel.Option {
+	return parallel.WithLogger(slog.New(New(l)))
+}
+
+func (h *Handler) Enabled(_ context.Context, l slog.Level) bool {
+	return level(l) >= h.logger.GetLevel()
+}
+
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	ev := h.logger.WithLevel(level(r.Level))
+	for _, a := range h.attrs {
+		addAttr(ev, a.prefix, a.attr)
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(ev, h.prefix, a)
+		return true
+	})
+
+	ev.Msg(r.Message)
+	return nil
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+	next.attrs = append([]prefixedAttr(nil), h.attrs...)
+	for _, a := range attrs {
+		next.attrs = append(next.attrs, prefixedAttr{prefix: h.prefix, attr: a})
+	}
+
+	return &next
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	next := *h
+	next.prefix = h.prefix + name + "."
+	return &next
+}
+
+func addAttr(ev *zerolog.Event, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	key := prefix + a.Key
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		if a.Key != "" {
+			prefix = key + "."
+		}
+
+		for _, ga := range a.Value.Group() {
+			addAttr(ev, prefix, ga)
+		}
+	case slog.KindString:
+		ev.Str(key, a.Value.String())
+	case slog.KindInt64:
+		ev.Int64(key, a.Value.Int64())
+	case slog.KindUint64:
+		ev.Uint64(key, a.Value.Uint64())
+	case slog.KindFloat64:
+		ev.Float64(key, a.Value.Float64())
+	case slog.KindBool:
+		ev.Bool(key, a.Value.Bool())
+	case slog.KindDuration:
+		ev.Dur(key, a.Value.Duration())
+	case slog.KindTime:
+		ev.Time(key, a.Value.Time())
+	default:
+		if err, ok := a.Value.Any().(error); ok {
+			ev.AnErr(key, err)
+			return
+		}
+
+		ev.Interface(key, a.Value.Any())
+	}
+}
+
+func level(l slog.Level) zerolog.Level {
+	switch {
+	case l >= slog.LevelError:
+		return zerolog.ErrorLevel
+	case l >= slog.LevelWarn:
+		return zerolog.WarnLevel
+	case l >= slog.LevelInfo:
+		return zerolog.InfoLevel
+	default:
+		return zerolog.DebugLevel
+	}
+}